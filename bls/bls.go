@@ -0,0 +1,356 @@
+// Package bls gets economic data like unemployment, PPI and CPI from the
+// U.S. Bureau of Labor Statistics public API.
+package bls
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"polyapi/httpx"
+)
+
+// BLSRequest is the body of a v2 timeseries request. The catalog, calculations and
+// annual averages are only returned with a registration key.
+type BLSRequest struct {
+	SeriesID        []string `json:"seriesid"`
+	StartYear       string   `json:"startyear"`
+	EndYear         string   `json:"endyear"`
+	RegistrationKey string   `json:"registrationkey,omitempty"`
+	Catalog         bool     `json:"catalog,omitempty"`
+	Calculations    bool     `json:"calculations,omitempty"`
+	AnnualAverage   bool     `json:"annualaverage,omitempty"`
+}
+
+// BLSResponse is the response of a timeseries request. Status is REQUEST_SUCCEEDED
+// when there are results; otherwise Message says why, e.g. the daily limit was reached.
+// Messages are also returned with results, e.g. for series that don't exist.
+type BLSResponse struct {
+	Status  string   `json:"status"`
+	Message []string `json:"message"`
+	Results struct {
+		Series []BLSSeries `json:"series"`
+	} `json:"Results"`
+}
+
+// err returns the error of a request that failed. BLS reports errors like an exceeded
+// daily limit with a 200 status.
+func (r BLSResponse) err() error {
+	if r.Status == "REQUEST_SUCCEEDED" {
+		return nil
+	}
+	if len(r.Message) == 0 {
+		return fmt.Errorf("BLS request failed: %s", r.Status)
+	}
+	return fmt.Errorf("BLS request failed: %s", strings.Join(r.Message, "; "))
+}
+
+// CheckBody returns the error of a timeseries response body whose request failed,
+// e.g. REQUEST_NOT_PROCESSED when the daily limit was reached. Bodies that aren't
+// JSON objects are left to the decoder.
+func CheckBody(body []byte) error {
+	var response BLSResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil
+	}
+	return response.err()
+}
+
+type BLSSeries struct {
+	SeriesID string      `json:"seriesID"`
+	Catalog  *BLSCatalog `json:"catalog,omitempty"`
+	Data     []BLSEntry  `json:"data"`
+}
+
+// BLSCatalog describes a series. It's returned when the catalog is requested with a registration key.
+type BLSCatalog struct {
+	SeriesTitle     string `json:"series_title"`
+	SeriesID        string `json:"series_id"`
+	Seasonality     string `json:"seasonality"`
+	SurveyName      string `json:"survey_name"`
+	MeasureDataType string `json:"measure_data_type"`
+}
+
+// BLSEntry is a value of a series in a period such as M07 (July), Q02, S01 or A01.
+// Latest is "true" for the latest period, and preliminary values are footnoted with code P.
+type BLSEntry struct {
+	Year         string           `json:"year"`
+	Period       string           `json:"period"`
+	Latest       string           `json:"latest,omitempty"`
+	Value        string           `json:"value"`
+	Calculations *BLSCalculations `json:"calculations,omitempty"`
+	Footnote     []BLSFootnote    `json:"footnotes"`
+}
+
+// BLSFootnote is a note on an entry, e.g. code P with the text "preliminary".
+type BLSFootnote struct {
+	Code string `json:"code,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// BLSCalculations are BLS's own net and percent changes of an entry over the last
+// 1, 3, 6 and 12 months, keyed by the number of months.
+type BLSCalculations struct {
+	NetChanges map[string]string `json:"net_changes"`
+	PctChanges map[string]string `json:"pct_changes"`
+}
+
+// MaxSeriesPerRequest is the most series the public API returns per request without a registration key.
+const MaxSeriesPerRequest = 25
+
+// MaxSeriesPerRequestWithKey is the most series per request with a registration key.
+const MaxSeriesPerRequestWithKey = 50
+
+// MaxYearsPerRequest is the most years the public API returns per request without a
+// registration key, and MaxYearsPerRequestWithKey with one.
+const (
+	MaxYearsPerRequest        = 10
+	MaxYearsPerRequestWithKey = 20
+)
+
+// DefaultBaseURL is the base URL of the BLS public API.
+const DefaultBaseURL = "https://api.bls.gov"
+
+// Client calls the BLS public API. The APIKey is optional; with one, requests can cover
+// more series and years and include the catalog, calculations and annual averages.
+// Register for a key at https://data.bls.gov/registrationEngine/
+type Client struct {
+	APIKey     string
+	HTTPClient *http.Client
+	BaseURL    string
+}
+
+// NewClient returns a BLS client using apiKey, which may be empty.
+func NewClient(apiKey string) *Client {
+	return &Client{APIKey: apiKey, HTTPClient: httpx.DefaultClient, BaseURL: DefaultBaseURL}
+}
+
+// MaxSeries returns the most series the client can request at once.
+func (c *Client) MaxSeries() int {
+	if c.APIKey != "" {
+		return MaxSeriesPerRequestWithKey
+	}
+	return MaxSeriesPerRequest
+}
+
+// MaxYears returns the most years the client can request at once.
+func (c *Client) MaxYears() int {
+	if c.APIKey != "" {
+		return MaxYearsPerRequestWithKey
+	}
+	return MaxYearsPerRequest
+}
+
+// processBLSData summarizes the latest value of a series with its changes from the
+// previous period, a year and five years earlier. Annual averages and missing values
+// are skipped, and a series without values has an empty summary.
+func processBLSData(series BLSSeries) Summary {
+
+	summary := Summary{SeriesID: series.SeriesID}
+	addDetails(&summary, series)
+
+	type value struct {
+		period      period
+		value       float64
+		preliminary bool
+	}
+
+	var values []value
+	for _, entry := range series.Data {
+		p, ok := parsePeriod(entry.Year, entry.Period)
+		if !ok {
+			continue
+		}
+		// Missing values are reported as "-"
+		v, err := strconv.ParseFloat(entry.Value, 64)
+		if err != nil {
+			continue
+		}
+		values = append(values, value{period: p, value: v, preliminary: isPreliminary(entry)})
+	}
+
+	if len(values) == 0 {
+		return summary
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].period.index() < values[j].period.index() })
+
+	latest := values[len(values)-1]
+	summary.LatestYear = strconv.Itoa(latest.period.Year)
+	summary.LatestPeriod = latest.period.Code()
+	summary.LatestPeriodName = latest.period.String()
+	summary.LatestValue = latest.value
+	summary.LatestPreliminary = latest.preliminary
+
+	if len(values) >= 2 {
+		previous := values[len(values)-2]
+		summary.PreviousYear = strconv.Itoa(previous.period.Year)
+		summary.PreviousPeriod = previous.period.Code()
+		summary.PreviousPeriodName = previous.period.String()
+		summary.PreviousValue = previous.value
+		summary.PreviousPreliminary = previous.preliminary
+		summary.Change, summary.PercentChange = change(latest.value, previous.value)
+	}
+
+	// Changes from the same period one and five years earlier, when they're in the data
+	byPeriod := make(map[period]float64, len(values))
+	for _, v := range values {
+		byPeriod[v.period] = v.value
+	}
+	if earlier, ok := byPeriod[latest.period.yearsBefore(1)]; ok {
+		change, percentChange := change(latest.value, earlier)
+		summary.TwelveMonthChange = &change
+		summary.TwelveMonthPercentChange = &percentChange
+	}
+	if earlier, ok := byPeriod[latest.period.yearsBefore(5)]; ok {
+		change, percentChange := change(latest.value, earlier)
+		summary.FiveYearChange = &change
+		summary.FiveYearPercentChange = &percentChange
+	}
+
+	return summary
+}
+
+// change returns the change from an earlier value and the percent change, which is 0
+// when the earlier value is 0.
+func change(latest, earlier float64) (float64, float64) {
+	if earlier == 0 {
+		return latest - earlier, 0
+	}
+	return latest - earlier, (latest - earlier) / earlier * 100
+}
+
+// addDetails adds the catalog title, the latest annual average and BLS's own changes of
+// the latest month from a series fetched with a registration key. Without one, the
+// series has none of them and the summary is unchanged.
+func addDetails(summary *Summary, series BLSSeries) {
+
+	if series.Catalog != nil && series.Catalog.SeriesTitle != "" {
+		summary.Title = series.Catalog.SeriesTitle
+	}
+
+	for _, entry := range series.Data {
+		if isAnnualAverage(entry.Period) {
+			if entry.Year > summary.AnnualAverageYear {
+				value, err := strconv.ParseFloat(entry.Value, 64)
+				if err == nil {
+					summary.AnnualAverageYear = entry.Year
+					summary.AnnualAverage = &value
+				}
+			}
+			continue
+		}
+
+		// The API returns the newest entry first, and calculates changes for it
+		if entry.Calculations != nil && summary.BLSChanges == nil {
+			summary.BLSChanges = parseCalculations(*entry.Calculations)
+		}
+	}
+}
+
+// parseCalculations returns BLS's net and percent changes, shortest period first.
+func parseCalculations(calculations BLSCalculations) []BLSChange {
+
+	var changes []BLSChange
+	for _, months := range []int{1, 3, 6, 12} {
+		key := strconv.Itoa(months)
+		net, err := strconv.ParseFloat(calculations.NetChanges[key], 64)
+		if err != nil {
+			continue
+		}
+		change := BLSChange{Months: months, NetChange: net}
+		if percent, err := strconv.ParseFloat(calculations.PctChanges[key], 64); err == nil {
+			change.PercentChange = &percent
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// Series gets the BLS series between startYear and endYear.
+func (c *Client) Series(ctx context.Context, seriesIDs []string, startYear, endYear int) ([]BLSSeries, error) {
+
+	// Define the data for the POST request
+	reqData := BLSRequest{
+		SeriesID:  seriesIDs,
+		StartYear: strconv.Itoa(startYear),
+		EndYear:   strconv.Itoa(endYear),
+	}
+	if c.APIKey != "" {
+		reqData.RegistrationKey = c.APIKey
+		reqData.Catalog = true
+		reqData.Calculations = true
+		reqData.AnnualAverage = true
+	}
+
+	// Marshal the request data into JSON
+	jsonData, err := json.Marshal(reqData)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling JSON: %w", err)
+	}
+
+	// Make the POST request
+	url := c.BaseURL + "/publicAPI/v2/timeseries/data/"
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making POST request: %w", err)
+	}
+	if err := httpx.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	// Unmarshal the JSON response
+	var blsResponse BLSResponse
+	err = json.Unmarshal(body, &blsResponse)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling JSON: %w", err)
+	}
+
+	if err := blsResponse.err(); err != nil {
+		return nil, err
+	}
+	for _, message := range blsResponse.Message {
+		log.Printf("BLS: %s", message)
+	}
+
+	return blsResponse.Results.Series, nil
+}
+
+// Data gets the BLS series between startYear and endYear and summarizes the latest changes.
+func (c *Client) Data(ctx context.Context, seriesIDs []string, startYear, endYear int) (Report, error) {
+
+	var report Report
+
+	series, err := c.Series(ctx, seriesIDs, startYear, endYear)
+	if err != nil {
+		return report, err
+	}
+
+	// Summarize the response data
+	for _, s := range series {
+		report.Series = append(report.Series, processBLSData(s))
+	}
+
+	return report, nil
+}
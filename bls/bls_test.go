@@ -0,0 +1,195 @@
+package bls
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"polyapi/store"
+)
+
+func TestData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/publicAPI/v2/timeseries/data/" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+
+		var request BLSRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("error decoding request: %v", err)
+		}
+		if request.StartYear != "2023" || request.EndYear != "2024" || len(request.SeriesID) != 2 {
+			t.Errorf("request = %+v", request)
+		}
+
+		http.ServeFile(w, r, filepath.Join("testdata", "timeseries.json"))
+	}))
+	defer server.Close()
+
+	client := NewClient("")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+
+	report, err := client.Data(context.Background(), []string{"CUSR0000SA0", "LNS14000000"}, 2023, 2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Series) != 2 {
+		t.Fatalf("got %d series, want 2", len(report.Series))
+	}
+
+	cpi := report.Series[0]
+	if cpi.SeriesID != "CUSR0000SA0" || cpi.LatestYear != "2024" || cpi.LatestPeriod != "M07" {
+		t.Errorf("CPI = %+v", cpi)
+	}
+	if cpi.LatestValue != 313.534 || cpi.PreviousValue != 314.175 {
+		t.Errorf("CPI values = %v, %v", cpi.LatestValue, cpi.PreviousValue)
+	}
+	if cpi.TwelveMonthChange == nil || math.Abs(*cpi.TwelveMonthChange-6.508) > 1e-9 {
+		t.Errorf("CPI 12-month change = %v, want 6.508", cpi.TwelveMonthChange)
+	}
+
+	// Without a year of data there is no 12-month change
+	unemployment := report.Series[1]
+	if math.Abs(unemployment.Change-0.2) > 1e-9 || unemployment.TwelveMonthChange != nil {
+		t.Errorf("unemployment = %+v", unemployment)
+	}
+}
+
+func TestProviderWithKey(t *testing.T) {
+	currentYear := time.Now().Year()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request BLSRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("error decoding request: %v", err)
+		}
+		// The first fetch gets 20 years with a key
+		if request.RegistrationKey != "test-key" || !request.Catalog || !request.Calculations || !request.AnnualAverage ||
+			request.StartYear != strconv.Itoa(currentYear-19) {
+			t.Errorf("request = %+v", request)
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", "timeseries_catalog.json"))
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	client := NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := NewProvider(client, s)
+
+	report, err := p.Data(context.Background(), []store.Series{{Source: "bls", SeriesID: "CUSR0000SA0", Label: "CUSR0000SA0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Series) != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	// A series labelled with its ID gets its catalog title
+	cpi := report.Series[0]
+	if cpi.Title != "All items in U.S. city average, all urban consumers, seasonally adjusted" {
+		t.Errorf("title = %q", cpi.Title)
+	}
+	// The annual average isn't a month
+	if cpi.LatestPeriod != "M07" || cpi.PreviousPeriod != "M06" || cpi.AnnualAverageYear != "2023" || *cpi.AnnualAverage != 304.704 {
+		t.Errorf("CPI = %+v", cpi)
+	}
+	if len(cpi.BLSChanges) != 4 || cpi.BLSChanges[0].NetChange != 0.465 || cpi.BLSChanges[3].Months != 12 || *cpi.BLSChanges[3].PercentChange != 2.9 {
+		t.Errorf("BLS changes = %+v", cpi.BLSChanges)
+	}
+
+	stored, err := s.Observations("bls", "CUSR0000SA0")
+	if err != nil || len(stored) != 3 {
+		t.Errorf("stored %d observations, want 3 without the annual average: %v", len(stored), err)
+	}
+}
+
+func TestProcessBLSData(t *testing.T) {
+	preliminary := []BLSFootnote{{Code: "P", Text: "preliminary"}}
+
+	// Quarterly data with an annual average, a missing value and a preliminary latest quarter
+	summary := processBLSData(BLSSeries{SeriesID: "PRS85006092", Data: []BLSEntry{
+		{Year: "2024", Period: "Q05", Value: "1.5"},
+		{Year: "2024", Period: "Q02", Latest: "true", Value: "2.3", Footnote: preliminary},
+		{Year: "2024", Period: "Q01", Value: "-"},
+		{Year: "2023", Period: "Q04", Value: "3.5"},
+		{Year: "2023", Period: "Q02", Value: "2.0"},
+	}})
+	if summary.LatestPeriod != "Q02" || summary.LatestPeriodName != "Q2 2024" || !summary.LatestPreliminary {
+		t.Errorf("latest = %+v", summary)
+	}
+	if summary.PreviousPeriod != "Q04" || summary.PreviousYear != "2023" || summary.PreviousPreliminary {
+		t.Errorf("previous = %+v", summary)
+	}
+	if summary.TwelveMonthChange == nil || math.Abs(*summary.TwelveMonthChange-0.3) > 1e-9 {
+		t.Errorf("12-month change = %v, want 0.3", summary.TwelveMonthChange)
+	}
+
+	// A single value has no changes, and a series without values is empty rather than a panic
+	single := processBLSData(BLSSeries{SeriesID: "LNS14000000", Data: []BLSEntry{{Year: "2024", Period: "M08", Value: "4.2"}}})
+	if single.LatestPeriodName != "August 2024" || single.PreviousPeriod != "" || single.Change != 0 {
+		t.Errorf("single = %+v", single)
+	}
+	empty := processBLSData(BLSSeries{SeriesID: "LNS14000000", Data: []BLSEntry{{Year: "2023", Period: "M13", Value: "3.6"}}})
+	if empty.LatestPeriod != "" || empty.LatestValue != 0 {
+		t.Errorf("empty = %+v", empty)
+	}
+}
+
+func TestSeriesRequestFailed(t *testing.T) {
+	failed := []byte(`{"status": "REQUEST_NOT_PROCESSED", "message": ["Request could not be serviced, as the daily threshold for total number of requests allocated to the user has been reached."], "Results": {}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(failed)
+	}))
+	defer server.Close()
+
+	client := NewClient("")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+
+	_, err := client.Series(context.Background(), []string{"CUSR0000SA0"}, 2023, 2024)
+	if err == nil || !strings.Contains(err.Error(), "daily threshold") {
+		t.Errorf("err = %v, want the BLS message", err)
+	}
+
+	// The failure isn't cached
+	if err := CheckBody(failed); err == nil {
+		t.Error("CheckBody accepted a failed request")
+	}
+	if err := CheckBody([]byte(`{"status": "REQUEST_SUCCEEDED", "Results": {"series": []}}`)); err != nil {
+		t.Errorf("CheckBody = %v for a successful request", err)
+	}
+}
+
+func TestRegionalSeries(t *testing.T) {
+	// A metro area with its own CPI, filed by LAUS under New York
+	nyc := RegionalSeries(store.Region{StateFIPS: "34", State: "NJ", CBSA: "35620", Metro: "New York-Newark-Jersey City, NY-NJ-PA Metro Area"})
+	want := []string{"LASST340000000000003", "LAUMT363562000000003", "CUURS12ASA0"}
+	if len(nyc) != len(want) {
+		t.Fatalf("series = %+v", nyc)
+	}
+	for i, id := range want {
+		if nyc[i].SeriesID != id {
+			t.Errorf("series %d = %s, want %s", i, nyc[i].SeriesID, id)
+		}
+	}
+
+	// Outside of a metro area, the CPI is the Census region's
+	rural := RegionalSeries(store.Region{StateFIPS: "50", State: "VT"})
+	if len(rural) != 2 || rural[0].SeriesID != "LASST500000000000003" || rural[1].SeriesID != "CUUR0100SA0" {
+		t.Errorf("series = %+v", rural)
+	}
+}
@@ -0,0 +1,93 @@
+package bls
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// period is the time a BLS value covers: a month (M01 to M12), a quarter (Q01 to Q04),
+// a half year (S01 and S02) or a year (A01).
+type period struct {
+	Year   int
+	Kind   byte
+	Number int
+}
+
+// periodsPerYear is the number of each kind of period in a year.
+var periodsPerYear = map[byte]int{'M': 12, 'Q': 4, 'S': 2, 'A': 1}
+
+// parsePeriod parses a BLS year and period. Annual averages of monthly, quarterly and
+// semiannual data (M13, Q05 and S03) aren't periods of the series and return false,
+// as do periods BLS doesn't document.
+func parsePeriod(year, code string) (period, bool) {
+
+	y, err := strconv.Atoi(year)
+	if err != nil || len(code) != 3 {
+		return period{}, false
+	}
+	n, err := strconv.Atoi(code[1:])
+	perYear, ok := periodsPerYear[code[0]]
+	if err != nil || !ok || n < 1 || n > perYear {
+		return period{}, false
+	}
+
+	return period{Year: y, Kind: code[0], Number: n}, true
+}
+
+// isAnnualAverage reports whether a BLS period is the annual average of a monthly,
+// quarterly or semiannual series.
+func isAnnualAverage(code string) bool {
+	return code == "M13" || code == "Q05" || code == "S03"
+}
+
+// month returns the first month of the period.
+func (p period) month() int {
+	return (p.Number-1)*12/periodsPerYear[p.Kind] + 1
+}
+
+// index orders periods of the same kind, oldest first.
+func (p period) index() int {
+	return p.Year*periodsPerYear[p.Kind] + p.Number
+}
+
+// yearsBefore returns the same period the given number of years earlier.
+func (p period) yearsBefore(years int) period {
+	p.Year -= years
+	return p
+}
+
+// Code returns the BLS period code, e.g. M07.
+func (p period) Code() string {
+	return fmt.Sprintf("%c%02d", p.Kind, p.Number)
+}
+
+// Date returns the first day of the period as YYYY-MM-DD.
+func (p period) Date() string {
+	return fmt.Sprintf("%04d-%02d-01", p.Year, p.month())
+}
+
+// String returns the period as e.g. "July 2024", "Q2 2024", "H1 2024" or "2024".
+func (p period) String() string {
+	switch p.Kind {
+	case 'M':
+		return fmt.Sprintf("%s %d", time.Month(p.Number), p.Year)
+	case 'Q':
+		return fmt.Sprintf("Q%d %d", p.Number, p.Year)
+	case 'S':
+		return fmt.Sprintf("H%d %d", p.Number, p.Year)
+	}
+	return strconv.Itoa(p.Year)
+}
+
+// isPreliminary reports whether an entry is footnoted as preliminary, e.g. the
+// latest months of the CPI and payrolls, which are revised later.
+func isPreliminary(entry BLSEntry) bool {
+	for _, footnote := range entry.Footnote {
+		if footnote.Code == "P" || strings.Contains(strings.ToLower(footnote.Text), "preliminary") {
+			return true
+		}
+	}
+	return false
+}
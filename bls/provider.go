@@ -0,0 +1,175 @@
+package bls
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"polyapi/output"
+	"polyapi/store"
+)
+
+// source identifies BLS series in the series_observations table.
+const source = "bls"
+
+// Provider gets the latest BLS economic data, keeping each series'
+// observations in the database so only new ones are fetched.
+type Provider struct {
+	Client *Client
+	Store  *store.Store
+}
+
+// NewProvider returns a BLS provider that stores observations in s.
+func NewProvider(client *Client, s *store.Store) *Provider {
+	return &Provider{Client: client, Store: s}
+}
+
+func (p *Provider) Name() string { return "bls" }
+
+func (p *Provider) Description() string {
+	return "BLS economic data like unemployment, PPI and CPI"
+}
+
+func (p *Provider) RequiredConfig() []string { return nil }
+
+// Fetch returns the latest values of the watchlist series; it takes no arguments.
+func (p *Provider) Fetch(ctx context.Context, args []string) (output.Result, error) {
+	return p.Latest(ctx)
+}
+
+// Latest updates and summarizes the BLS series on the watchlist.
+func (p *Provider) Latest(ctx context.Context) (Report, error) {
+
+	watchlist, err := p.Store.Watchlist(source)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return p.Data(ctx, watchlist)
+}
+
+// Data fetches the observations of each series since the latest stored one, saves them
+// and summarizes the stored series. If the API can't be reached the stored observations are used.
+func (p *Provider) Data(ctx context.Context, watchlist []store.Series) (Report, error) {
+
+	var report Report
+
+	var seriesIDs []string
+	for _, series := range watchlist {
+		seriesIDs = append(seriesIDs, series.SeriesID)
+	}
+
+	// The catalog and calculations of the fetched series, when there's a registration key
+	fetched := map[string]BLSSeries{}
+
+	var updateErr error
+	maxSeries := p.Client.MaxSeries()
+	for start := 0; start < len(seriesIDs); start += maxSeries {
+		if err := p.update(ctx, seriesIDs[start:min(start+maxSeries, len(seriesIDs))], fetched); err != nil {
+			updateErr = err
+		}
+	}
+
+	for _, watched := range watchlist {
+		stored, err := p.Store.Observations(source, watched.SeriesID)
+		if err != nil {
+			return report, err
+		}
+		if len(stored) == 0 {
+			continue
+		}
+
+		series := BLSSeries{SeriesID: watched.SeriesID}
+		for _, observation := range stored {
+			entry := BLSEntry{
+				Year:   observation.Date[:4],
+				Period: observation.Period,
+				Value:  strconv.FormatFloat(observation.Value, 'f', -1, 64),
+			}
+			if observation.Preliminary {
+				entry.Footnote = []BLSFootnote{{Code: "P", Text: "preliminary"}}
+			}
+			series.Data = append(series.Data, entry)
+		}
+
+		summary := processBLSData(series)
+		addDetails(&summary, fetched[watched.SeriesID])
+		// Series added without a label are labelled with their ID, so prefer the catalog title
+		if watched.Label != watched.SeriesID || summary.Title == "" {
+			summary.Title = watched.Label
+		}
+		summary.Units = watched.Units
+		report.Series = append(report.Series, summary)
+	}
+
+	if updateErr != nil {
+		if len(report.Series) == 0 {
+			return report, updateErr
+		}
+		log.Printf("Error updating BLS series, using stored observations: %v", updateErr)
+	}
+
+	return report, nil
+}
+
+// update fetches and saves the observations of the series from the year of the
+// oldest latest stored observation, since the API only filters by year, and adds
+// the fetched series to fetched. Series without stored observations get as many
+// years as the API returns: 10, or 20 with a registration key.
+func (p *Provider) update(ctx context.Context, seriesIDs []string, fetched map[string]BLSSeries) error {
+
+	currentYear := time.Now().Year()
+	startYear := currentYear
+
+	for _, id := range seriesIDs {
+		latest, err := p.Store.LatestObservationDate(source, id)
+		if err != nil {
+			return err
+		}
+		if latest == "" {
+			startYear = currentYear - p.Client.MaxYears() + 1
+			break
+		}
+		year, err := strconv.Atoi(latest[:4])
+		if err != nil {
+			return fmt.Errorf("invalid stored date %q for %s: %w", latest, id, err)
+		}
+		startYear = min(startYear, year)
+	}
+	startYear = max(startYear, currentYear-p.Client.MaxYears()+1)
+
+	series, err := p.Client.Series(ctx, seriesIDs, startYear, currentYear)
+	if err != nil {
+		return err
+	}
+
+	var observations []store.Observation
+	for _, s := range series {
+		fetched[s.SeriesID] = s
+		for _, entry := range s.Data {
+			// Annual averages aren't stored, and missing values are reported as "-"
+			period, ok := parsePeriod(entry.Year, entry.Period)
+			if !ok {
+				continue
+			}
+			value, err := strconv.ParseFloat(entry.Value, 64)
+			if err != nil {
+				continue
+			}
+			observations = append(observations, store.Observation{Source: source, SeriesID: s.SeriesID, Date: period.Date(),
+				Value: value, Period: period.Code(), Preliminary: isPreliminary(entry)})
+		}
+	}
+
+	if err := p.Store.SaveObservations(observations); err != nil {
+		return err
+	}
+	for _, id := range seriesIDs {
+		if err := p.Store.MarkSeriesFetched(source, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package bls
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"polyapi/store"
+)
+
+// state is a state's FIPS code and the Census region whose CPI covers it.
+type state struct {
+	FIPS   string
+	Region string
+}
+
+// Census regions with their CPI area codes
+const (
+	northeast = "0100"
+	midwest   = "0200"
+	south     = "0300"
+	west      = "0400"
+)
+
+// regionNames are the names of the CPI's Census regions.
+var regionNames = map[string]string{
+	northeast: "Northeast",
+	midwest:   "Midwest",
+	south:     "South",
+	west:      "West",
+}
+
+// states are the states, DC and Puerto Rico by abbreviation. Puerto Rico isn't in a
+// Census region, so it has no regional CPI.
+var states = map[string]state{
+	"AL": {"01", south}, "AK": {"02", west}, "AZ": {"04", west}, "AR": {"05", south},
+	"CA": {"06", west}, "CO": {"08", west}, "CT": {"09", northeast}, "DE": {"10", south},
+	"DC": {"11", south}, "FL": {"12", south}, "GA": {"13", south}, "HI": {"15", west},
+	"ID": {"16", west}, "IL": {"17", midwest}, "IN": {"18", midwest}, "IA": {"19", midwest},
+	"KS": {"20", midwest}, "KY": {"21", south}, "LA": {"22", south}, "ME": {"23", northeast},
+	"MD": {"24", south}, "MA": {"25", northeast}, "MI": {"26", midwest}, "MN": {"27", midwest},
+	"MS": {"28", south}, "MO": {"29", midwest}, "MT": {"30", west}, "NE": {"31", midwest},
+	"NV": {"32", west}, "NH": {"33", northeast}, "NJ": {"34", northeast}, "NM": {"35", west},
+	"NY": {"36", northeast}, "NC": {"37", south}, "ND": {"38", midwest}, "OH": {"39", midwest},
+	"OK": {"40", south}, "OR": {"41", west}, "PA": {"42", northeast}, "RI": {"44", northeast},
+	"SC": {"45", south}, "SD": {"46", midwest}, "TN": {"47", south}, "TX": {"48", south},
+	"UT": {"49", west}, "VT": {"50", northeast}, "VA": {"51", south}, "WA": {"53", west},
+	"WV": {"54", south}, "WI": {"55", midwest}, "WY": {"56", west}, "PR": {"72", ""},
+}
+
+// cpiArea is a metro area the CPI is published for.
+type cpiArea struct {
+	Code string
+	Name string
+}
+
+// cpiAreas are the metro areas with their own CPI by CBSA code. Some are published
+// every other month rather than monthly.
+var cpiAreas = map[string]cpiArea{
+	"14460": {"S11A", "Boston-Cambridge-Newton"},
+	"35620": {"S12A", "New York-Newark-Jersey City"},
+	"37980": {"S12B", "Philadelphia-Camden-Wilmington"},
+	"16980": {"S23A", "Chicago-Naperville-Elgin"},
+	"19820": {"S23B", "Detroit-Warren-Dearborn"},
+	"33460": {"S24A", "Minneapolis-St.Paul-Bloomington"},
+	"41180": {"S24B", "St. Louis"},
+	"47900": {"S35A", "Washington-Arlington-Alexandria"},
+	"33100": {"S35B", "Miami-Fort Lauderdale-West Palm Beach"},
+	"12060": {"S35C", "Atlanta-Sandy Springs-Roswell"},
+	"45300": {"S35D", "Tampa-St. Petersburg-Clearwater"},
+	"12580": {"S35E", "Baltimore-Columbia-Towson"},
+	"19100": {"S37A", "Dallas-Fort Worth-Arlington"},
+	"26420": {"S37B", "Houston-The Woodlands-Sugar Land"},
+	"38060": {"S48A", "Phoenix-Mesa-Scottsdale"},
+	"19740": {"S48B", "Denver-Aurora-Lakewood"},
+	"31080": {"S49A", "Los Angeles-Long Beach-Anaheim"},
+	"41860": {"S49B", "San Francisco-Oakland-Hayward"},
+	"40140": {"S49C", "Riverside-San Bernardino-Ontario"},
+	"42660": {"S49D", "Seattle-Tacoma-Bellevue"},
+	"41740": {"S49E", "San Diego-Carlsbad"},
+	"46520": {"S49F", "Urban Hawaii"},
+	"11260": {"S49G", "Urban Alaska"},
+}
+
+// RegionalSeries returns the BLS series for a region: the state's unemployment rate
+// from LAUS (Local Area Unemployment Statistics), the metro area's unemployment rate,
+// and the metro area's CPI or, if it has none, its Census region's CPI.
+func RegionalSeries(region store.Region) []store.Series {
+
+	var series []store.Series
+
+	if st, ok := states[region.State]; ok {
+		series = append(series, store.Series{
+			Source:    source,
+			SeriesID:  "LASST" + st.FIPS + "00000000000" + "03",
+			Label:     fmt.Sprintf("Unemployment rate, %s", region.State),
+			Frequency: "Monthly",
+			Units:     "Percent",
+		})
+	}
+
+	// LAUS files metro areas that cross state lines under their principal state,
+	// the first one in the name, e.g. NY in "New York-Newark-Jersey City, NY-NJ-PA"
+	if region.CBSA != "" {
+		if st, ok := states[principalState(region.Metro)]; ok {
+			series = append(series, store.Series{
+				Source:    source,
+				SeriesID:  "LAUMT" + st.FIPS + region.CBSA + "000000" + "03",
+				Label:     fmt.Sprintf("Unemployment rate, %s", region.Metro),
+				Frequency: "Monthly",
+				Units:     "Percent",
+			})
+		}
+	}
+
+	if area, ok := cpiAreas[region.CBSA]; ok {
+		series = append(series, store.Series{
+			Source:   source,
+			SeriesID: "CUUR" + area.Code + "SA0",
+			Label:    fmt.Sprintf("Consumer Price Index (CPI), %s", area.Name),
+			Units:    "Index",
+		})
+	} else if st, ok := states[region.State]; ok && st.Region != "" {
+		series = append(series, store.Series{
+			Source:    source,
+			SeriesID:  "CUUR" + st.Region + "SA0",
+			Label:     fmt.Sprintf("Consumer Price Index (CPI), %s region", regionNames[st.Region]),
+			Frequency: "Monthly",
+			Units:     "Index",
+		})
+	}
+
+	return series
+}
+
+// principalState returns the abbreviation of the first state of a metro area's name,
+// e.g. "NY" for "New York-Newark-Jersey City, NY-NJ-PA Metro Area".
+func principalState(metro string) string {
+
+	i := strings.LastIndex(metro, ", ")
+	if i < 0 || len(metro) < i+4 {
+		return ""
+	}
+	return metro[i+2 : i+4]
+}
+
+// Regional fetches and summarizes the unemployment rates and CPI of a region.
+// The series aren't added to the watchlist, but their observations are stored.
+func (p *Provider) Regional(ctx context.Context, region store.Region) (Report, error) {
+
+	series := RegionalSeries(region)
+	if len(series) == 0 {
+		return Report{}, fmt.Errorf("no BLS series for state %q", region.State)
+	}
+
+	return p.Data(ctx, series)
+}
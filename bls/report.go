@@ -0,0 +1,150 @@
+package bls
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"polyapi/output"
+)
+
+// BLSChange is the net and percent change of a series over a number of months as
+// calculated by BLS.
+type BLSChange struct {
+	Months        int      `json:"months"`
+	NetChange     float64  `json:"net_change"`
+	PercentChange *float64 `json:"percent_change,omitempty"`
+}
+
+// Summary is the latest value of a BLS series with its changes from the previous period
+// and a year earlier. Periods are BLS codes like M07 or Q02 with names like "July 2024",
+// and preliminary values are flagged. With a registration key it also has BLS's own
+// changes and the latest annual average.
+type Summary struct {
+	SeriesID                 string      `json:"series_id"`
+	Title                    string      `json:"title,omitempty"`
+	Units                    string      `json:"units,omitempty"`
+	LatestYear               string      `json:"latest_year"`
+	LatestPeriod             string      `json:"latest_period"`
+	LatestPeriodName         string      `json:"latest_period_name"`
+	LatestValue              float64     `json:"latest_value"`
+	LatestPreliminary        bool        `json:"latest_preliminary"`
+	PreviousYear             string      `json:"previous_year"`
+	PreviousPeriod           string      `json:"previous_period"`
+	PreviousPeriodName       string      `json:"previous_period_name"`
+	PreviousValue            float64     `json:"previous_value"`
+	PreviousPreliminary      bool        `json:"previous_preliminary"`
+	Change                   float64     `json:"change"`
+	PercentChange            float64     `json:"percent_change"`
+	TwelveMonthChange        *float64    `json:"twelve_month_change,omitempty"`
+	TwelveMonthPercentChange *float64    `json:"twelve_month_percent_change,omitempty"`
+	FiveYearChange           *float64    `json:"five_year_change,omitempty"`
+	FiveYearPercentChange    *float64    `json:"five_year_percent_change,omitempty"`
+	BLSChanges               []BLSChange `json:"bls_changes,omitempty"`
+	AnnualAverageYear        string      `json:"annual_average_year,omitempty"`
+	AnnualAverage            *float64    `json:"annual_average,omitempty"`
+}
+
+// Report is the summary of every BLS series fetched.
+type Report struct {
+	Series []Summary `json:"series"`
+}
+
+// PrintTable prints each series with its changes.
+func (r Report) PrintTable(w io.Writer) {
+
+	if len(r.Series) == 0 {
+		fmt.Fprintln(w, "\nNo BLS series on the watchlist. Add one with: polyapi series add bls SERIES_ID --label LABEL")
+		fmt.Fprintln(w)
+	}
+
+	for _, series := range r.Series {
+		if series.Title != "" {
+			fmt.Fprintf(w, "\n%s:\n", series.Title)
+		} else {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, "Series: %s\n", series.SeriesID)
+		if series.Units != "" {
+			fmt.Fprintf(w, "Units: %s\n", series.Units)
+		}
+		if series.LatestPeriodName == "" {
+			fmt.Fprintln(w, "No values returned for this series")
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintf(w, "Latest: %s - Value: %f%s\n", series.LatestPeriodName, series.LatestValue, preliminary(series.LatestPreliminary))
+		if series.PreviousPeriodName != "" {
+			fmt.Fprintf(w, "Previous: %s - Value: %f%s\n", series.PreviousPeriodName, series.PreviousValue, preliminary(series.PreviousPreliminary))
+			fmt.Fprintf(w, "Change: %.2f (%.2f%%)\n", series.Change, series.PercentChange)
+		}
+
+		if series.TwelveMonthChange != nil {
+			fmt.Fprintf(w, "12-month change: %.2f (%.2f%%)\n", *series.TwelveMonthChange, *series.TwelveMonthPercentChange)
+		} else {
+			fmt.Fprintln(w, "Not enough data to calculate 12-month change")
+		}
+		if series.FiveYearChange != nil {
+			fmt.Fprintf(w, "5-year change: %.2f (%.2f%%)\n", *series.FiveYearChange, *series.FiveYearPercentChange)
+		}
+		for _, change := range series.BLSChanges {
+			fmt.Fprintf(w, "BLS %d-month change: %.2f", change.Months, change.NetChange)
+			if change.PercentChange != nil {
+				fmt.Fprintf(w, " (%.2f%%)", *change.PercentChange)
+			}
+			fmt.Fprintln(w)
+		}
+		if series.AnnualAverage != nil {
+			fmt.Fprintf(w, "%s annual average: %f\n", series.AnnualAverageYear, *series.AnnualAverage)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// CSVHeader returns the series columns.
+func (r Report) CSVHeader() []string {
+	return []string{"series_id", "title", "units", "latest_year", "latest_period", "latest_value", "latest_preliminary",
+		"previous_year", "previous_period", "previous_value", "previous_preliminary", "change", "percent_change", "twelve_month_change", "twelve_month_percent_change",
+		"five_year_change", "five_year_percent_change", "bls_12_month_change", "bls_12_month_percent_change",
+		"annual_average_year", "annual_average"}
+}
+
+// CSVRows returns one row per series.
+func (r Report) CSVRows() [][]string {
+	var rows [][]string
+	for _, series := range r.Series {
+		rows = append(rows, []string{series.SeriesID, series.Title, series.Units, series.LatestYear, series.LatestPeriod,
+			output.FormatFloat(series.LatestValue), strconv.FormatBool(series.LatestPreliminary),
+			series.PreviousYear, series.PreviousPeriod, output.FormatFloat(series.PreviousValue), strconv.FormatBool(series.PreviousPreliminary),
+			output.FormatFloat(series.Change), output.FormatFloat(series.PercentChange),
+			output.FormatOptional(series.TwelveMonthChange), output.FormatOptional(series.TwelveMonthPercentChange),
+			output.FormatOptional(series.FiveYearChange), output.FormatOptional(series.FiveYearPercentChange),
+			blsChange(series, 12, false), blsChange(series, 12, true),
+			series.AnnualAverageYear, output.FormatOptional(series.AnnualAverage)})
+	}
+	return rows
+}
+
+// blsChange formats BLS's own net or percent change over a number of months for CSV,
+// leaving it blank when it wasn't returned.
+func blsChange(series Summary, months int, percent bool) string {
+	for _, change := range series.BLSChanges {
+		if change.Months != months {
+			continue
+		}
+		if percent {
+			return output.FormatOptional(change.PercentChange)
+		}
+		return output.FormatFloat(change.NetChange)
+	}
+	return ""
+}
+
+// preliminary marks a preliminary value in the table.
+func preliminary(preliminary bool) string {
+	if preliminary {
+		return " (preliminary)"
+	}
+	return ""
+}
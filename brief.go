@@ -0,0 +1,482 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"polyapi/espn"
+	"polyapi/fred"
+	"polyapi/output"
+	"polyapi/pool"
+	"polyapi/provider"
+	"polyapi/store"
+	"polyapi/treasury"
+)
+
+// defaultLeagues are the leagues shown by brief when neither --leagues nor
+// POLYAPI_LEAGUES is set.
+const defaultLeagues = "nfl,mlb,nba,nhl"
+
+// Briefing is the morning summary of the weather, markets, Treasury yield curve,
+// economy and games. A section whose provider failed has an error instead of values.
+type Briefing struct {
+	Date     string           `json:"date"`
+	Weather  BriefWeather     `json:"weather"`
+	Markets  BriefMarkets     `json:"markets"`
+	Treasury BriefTreasury    `json:"treasury"`
+	Economy  []BriefIndicator `json:"economy"`
+	Sports   []BriefLeague    `json:"sports"`
+}
+
+// BriefWeather is the current temperature and conditions at the default address
+// with the forecast for the rest of the day.
+type BriefWeather struct {
+	Address     string `json:"address,omitempty"`
+	Temperature string `json:"temperature,omitempty"`
+	Conditions  string `json:"conditions,omitempty"`
+	Forecast    string `json:"forecast,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BriefQuote is the price of a saved ticker symbol and its change on the day.
+type BriefQuote struct {
+	Symbol        string  `json:"symbol"`
+	Price         float64 `json:"price"`
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"change_percent"`
+	Error         string  `json:"error,omitempty"`
+}
+
+// BriefMarkets are the quotes of every saved ticker symbol.
+type BriefMarkets struct {
+	Quotes []BriefQuote `json:"quotes"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// BriefTreasury are the spreads of the latest Treasury yield curve.
+type BriefTreasury struct {
+	Date     string               `json:"date,omitempty"`
+	Spreads  []treasury.CurveRate `json:"spreads,omitempty"`
+	Inverted bool                 `json:"inverted"`
+	Error    string               `json:"error,omitempty"`
+}
+
+// BriefIndicator is the latest value of an economic indicator, in percent, with
+// its change from the previous period when that's meaningful.
+type BriefIndicator struct {
+	Name        string   `json:"name"`
+	Period      string   `json:"period,omitempty"`
+	Value       float64  `json:"value"`
+	Change      *float64 `json:"change,omitempty"`
+	Preliminary bool     `json:"preliminary,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// BriefLeague are a league's games today.
+type BriefLeague struct {
+	League string      `json:"league"`
+	Games  []espn.Game `json:"games"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// briefCommand prints the morning briefing. --address picks the address for the weather,
+// by default the one looked up most recently, and --leagues the leagues for the games.
+func (a *app) briefCommand(ctx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("brief", flag.ContinueOnError)
+	address := flags.String("address", "", "street address for the weather, by default the last one looked up")
+	leagues := flags.String("leagues", favoriteLeagues(), "comma-separated leagues for today's games, e.g. nfl,nba")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	leagueKeys, err := parseLeagues(*leagues)
+	if err != nil {
+		return err
+	}
+
+	return render(a.brief(ctx, *address, leagueKeys))
+}
+
+// favoriteLeagues returns the leagues set in POLYAPI_LEAGUES, or the default leagues.
+func favoriteLeagues() string {
+	if leagues := os.Getenv("POLYAPI_LEAGUES"); leagues != "" {
+		return leagues
+	}
+	return defaultLeagues
+}
+
+// parseLeagues returns the league keys of comma-separated league names, e.g. "nfl,nba".
+func parseLeagues(names string) ([]string, error) {
+
+	var leagues []string
+	for _, name := range strings.Split(names, ",") {
+		league, err := espn.ParseLeague(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		leagues = append(leagues, league)
+	}
+	return leagues, nil
+}
+
+// brief fetches every section of the briefing at the same time.
+func (a *app) brief(ctx context.Context, address string, leagues []string) Briefing {
+
+	briefing := Briefing{
+		Date: time.Now().Format("2006-01-02"),
+		Economy: []BriefIndicator{
+			{Name: "CPI, 12-month change"},
+			{Name: "Unemployment rate"},
+			{Name: "Federal funds rate"},
+		},
+	}
+
+	sections := []func(ctx context.Context){
+		func(ctx context.Context) { briefing.Weather = a.briefWeather(ctx, address) },
+		func(ctx context.Context) { briefing.Markets = a.briefMarkets(ctx) },
+		func(ctx context.Context) { briefing.Treasury = a.briefTreasury(ctx) },
+		func(ctx context.Context) { a.briefBLS(ctx, briefing.Economy[:2]) },
+		func(ctx context.Context) { a.briefFRED(ctx, &briefing.Economy[2]) },
+		func(ctx context.Context) { briefing.Sports = a.briefSports(ctx, leagues) },
+	}
+	pool.Run(ctx, len(sections), len(sections), func(ctx context.Context, i int) {
+		sections[i](ctx)
+	})
+
+	return briefing
+}
+
+// briefWeather gets the weather for an address, or for the saved address looked up most recently.
+func (a *app) briefWeather(ctx context.Context, text string) BriefWeather {
+
+	var brief BriefWeather
+
+	address, err := a.defaultAddress(ctx, text)
+	if err != nil {
+		brief.Error = err.Error()
+		return brief
+	}
+	brief.Address = address.MatchedAddress
+
+	report, points, err := a.weather.Weather(ctx, address)
+	if err != nil {
+		brief.Error = err.Error()
+		return brief
+	}
+	brief.Temperature = report.LatestTemperature
+	for _, station := range report.Stations {
+		if station.Description != "" {
+			brief.Conditions = station.Description
+			break
+		}
+	}
+
+	forecast, err := a.weather.Client.Forecast(ctx, points)
+	if err == nil && len(forecast) > 0 {
+		brief.Forecast = fmt.Sprintf("%s: %s", forecast[0].Name, forecast[0].ShortForecast)
+	}
+
+	return brief
+}
+
+// defaultAddress geocodes and saves text if it's given, and otherwise returns the saved
+// address whose weather was looked up most recently.
+func (a *app) defaultAddress(ctx context.Context, text string) (store.Address, error) {
+
+	if text != "" {
+		return a.weather.SaveAddress(ctx, text)
+	}
+
+	addresses, err := a.store.Addresses()
+	if err != nil {
+		return store.Address{}, err
+	}
+	if len(addresses) == 0 {
+		return store.Address{}, fmt.Errorf("no saved address, use --address")
+	}
+
+	latest := addresses[0]
+	for _, address := range addresses[1:] {
+		if address.UpdatedAt > latest.UpdatedAt {
+			latest = address
+		}
+	}
+	return latest, nil
+}
+
+// briefMarkets quotes every saved ticker symbol and adds the quotes to their price
+// history. Only the price is fetched, without the company overview, to save Alpha
+// Vantage requests.
+func (a *app) briefMarkets(ctx context.Context) BriefMarkets {
+
+	var brief BriefMarkets
+
+	if err := provider.CheckConfig(a.stocks); err != nil {
+		brief.Error = err.Error()
+		return brief
+	}
+
+	tickers, err := a.store.Tickers()
+	if err != nil {
+		brief.Error = err.Error()
+		return brief
+	}
+	if len(tickers) == 0 {
+		brief.Error = "no saved ticker symbols, add one with: polyapi quote SYMBOL"
+		return brief
+	}
+
+	brief.Quotes = make([]BriefQuote, len(tickers))
+	pool.Run(ctx, pool.Size(len(tickers)), len(tickers), func(ctx context.Context, i int) {
+		quote := BriefQuote{Symbol: tickers[i].Ticker}
+		q, err := a.stocks.Price(ctx, tickers[i].Ticker)
+		if err != nil {
+			quote.Error = err.Error()
+		} else {
+			quote.Price, quote.Change, quote.ChangePercent = q.Price, q.Change, q.ChangePercent
+		}
+		brief.Quotes[i] = quote
+	})
+
+	return brief
+}
+
+// briefTreasury gets the spreads of the latest yield curve.
+func (a *app) briefTreasury(ctx context.Context) BriefTreasury {
+
+	curve, err := a.treasury.YieldCurve(ctx)
+	if err != nil {
+		return BriefTreasury{Error: err.Error()}
+	}
+
+	return BriefTreasury{Date: curve.Date, Spreads: curve.Spreads, Inverted: curve.Inverted}
+}
+
+// briefBLS sets the CPI's 12-month change and the unemployment rate. The 12-month
+// change is of the CPI that isn't seasonally adjusted, like the published headline
+// inflation figure.
+func (a *app) briefBLS(ctx context.Context, indicators []BriefIndicator) {
+
+	series := []store.Series{
+		{Source: "bls", SeriesID: "CUUR0000SA0", Label: indicators[0].Name},
+		{Source: "bls", SeriesID: "LNS14000000", Label: indicators[1].Name},
+	}
+
+	report, err := a.bls.Data(ctx, series)
+	if err == nil && len(report.Series) != len(series) {
+		err = fmt.Errorf("no BLS data available")
+	}
+	if err != nil {
+		indicators[0].Error = err.Error()
+		indicators[1].Error = err.Error()
+		return
+	}
+
+	cpi := report.Series[0]
+	if cpi.TwelveMonthPercentChange != nil {
+		indicators[0].Period = cpi.LatestPeriodName
+		indicators[0].Value = *cpi.TwelveMonthPercentChange
+		indicators[0].Preliminary = cpi.LatestPreliminary
+	} else {
+		indicators[0].Error = "not enough data to calculate the 12-month change"
+	}
+
+	unemployment := report.Series[1]
+	indicators[1].Period = unemployment.LatestPeriodName
+	indicators[1].Value = unemployment.LatestValue
+	indicators[1].Preliminary = unemployment.LatestPreliminary
+	if unemployment.PreviousPeriod != "" {
+		indicators[1].Change = &unemployment.Change
+	}
+}
+
+// briefFRED sets the federal funds rate.
+func (a *app) briefFRED(ctx context.Context, indicator *BriefIndicator) {
+
+	summary, err := a.fred.Series(ctx, fred.SeriesInfo{ID: "FEDFUNDS", Title: indicator.Name, Frequency: "Monthly", Units: "Percent"})
+	if err != nil {
+		indicator.Error = err.Error()
+		return
+	}
+
+	value, err := strconv.ParseFloat(summary.Value, 64)
+	if err != nil {
+		indicator.Error = "no federal funds rate available"
+		return
+	}
+	indicator.Period = summary.Date
+	indicator.Value = value
+	if summary.Previous != nil {
+		indicator.Change = &summary.Previous.Change
+	}
+}
+
+// briefSports gets the games of each league scheduled for today, in local time.
+func (a *app) briefSports(ctx context.Context, leagues []string) []BriefLeague {
+
+	today := time.Now().Format("2006-01-02")
+
+	briefs := make([]BriefLeague, len(leagues))
+	pool.Run(ctx, pool.Size(len(leagues)), len(leagues), func(ctx context.Context, i int) {
+		brief := BriefLeague{League: leagues[i]}
+		schedule, err := a.espn.Client.Schedule(ctx, leagues[i])
+		if err != nil {
+			brief.Error = err.Error()
+		}
+		for _, game := range schedule.Games {
+			start, err := time.Parse("2006-01-02T15:04Z", game.Date)
+			if err == nil && start.Local().Format("2006-01-02") == today {
+				brief.Games = append(brief.Games, game)
+			}
+		}
+		briefs[i] = brief
+	})
+
+	return briefs
+}
+
+// PrintTable prints each section in a few lines, with rises in green, falls and
+// an inverted yield curve in red, and unavailable sections in yellow.
+func (b Briefing) PrintTable(w io.Writer) {
+
+	date, _ := time.Parse("2006-01-02", b.Date)
+	fmt.Fprintf(w, "\n%s\n", output.Color(output.Bold, "Morning briefing for "+date.Format("Monday, January 2, 2006")))
+
+	fmt.Fprintf(w, "\n%s %s\n", output.Color(output.Bold, "Weather:"), b.Weather.Address)
+	if b.Weather.Error != "" {
+		printUnavailable(w, b.Weather.Error)
+	} else {
+		fmt.Fprintf(w, "  %s", b.Weather.Temperature)
+		if b.Weather.Conditions != "" {
+			fmt.Fprintf(w, ", %s", b.Weather.Conditions)
+		}
+		fmt.Fprintln(w)
+		if b.Weather.Forecast != "" {
+			fmt.Fprintf(w, "  %s\n", b.Weather.Forecast)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", output.Color(output.Bold, "Markets:"))
+	if b.Markets.Error != "" {
+		printUnavailable(w, b.Markets.Error)
+	}
+	for _, quote := range b.Markets.Quotes {
+		if quote.Error != "" {
+			fmt.Fprintf(w, "  %-8s %s\n", quote.Symbol, output.Color(output.Yellow, quote.Error))
+			continue
+		}
+		change := fmt.Sprintf("%+.2f (%+.2f%%)", quote.Change, quote.ChangePercent)
+		fmt.Fprintf(w, "  %-8s %10.2f  %s\n", quote.Symbol, quote.Price, output.ColorChange(quote.Change, change))
+	}
+
+	fmt.Fprintf(w, "\n%s %s\n", output.Color(output.Bold, "Treasury yield curve:"), b.Treasury.Date)
+	if b.Treasury.Error != "" {
+		printUnavailable(w, b.Treasury.Error)
+	} else {
+		fmt.Fprint(w, " ")
+		for _, spread := range b.Treasury.Spreads {
+			fmt.Fprintf(w, " %s %s ", spread.Name, output.ColorChange(spread.Value, fmt.Sprintf("%+.2f", spread.Value)))
+		}
+		if b.Treasury.Inverted {
+			fmt.Fprint(w, " ", output.Color(output.Red, "INVERTED"))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", output.Color(output.Bold, "Economy:"))
+	for _, indicator := range b.Economy {
+		if indicator.Error != "" {
+			fmt.Fprintf(w, "  %-22s %s\n", indicator.Name, output.Color(output.Yellow, indicator.Error))
+			continue
+		}
+		fmt.Fprintf(w, "  %-22s %6.2f%%  %s", indicator.Name, indicator.Value, indicator.Period)
+		if indicator.Change != nil {
+			fmt.Fprintf(w, " (%+.2f)", *indicator.Change)
+		}
+		if indicator.Preliminary {
+			fmt.Fprint(w, " (preliminary)")
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", output.Color(output.Bold, "Today's games:"))
+	for _, league := range b.Sports {
+		if league.Error != "" {
+			fmt.Fprintf(w, "  %-10s %s\n", league.League, output.Color(output.Yellow, league.Error))
+			continue
+		}
+		if len(league.Games) == 0 {
+			fmt.Fprintf(w, "  %-10s no games today\n", league.League)
+		}
+		for _, game := range league.Games {
+			fmt.Fprintf(w, "  %-10s %-12s %s\n", league.League, game.ShortName, gameScore(game))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// printUnavailable prints why a section couldn't be fetched.
+func printUnavailable(w io.Writer, err string) {
+	fmt.Fprintf(w, "  %s\n", output.Color(output.Yellow, "unavailable: "+err))
+}
+
+// gameScore formats the score of a game once it has started, followed by its status.
+func gameScore(game espn.Game) string {
+	if game.State == "pre" {
+		return game.Status
+	}
+	var teams []string
+	for _, team := range game.Competitors {
+		teams = append(teams, team.Name+" "+team.Score)
+	}
+	return strings.Join(teams, ", ") + " - " + game.Status
+}
+
+// CSVHeader returns the briefing columns.
+func (b Briefing) CSVHeader() []string {
+	return []string{"section", "name", "value", "change", "detail", "error"}
+}
+
+// CSVRows returns one row per value in each section, or the section's error.
+func (b Briefing) CSVRows() [][]string {
+
+	rows := [][]string{{"weather", b.Weather.Address, b.Weather.Temperature, "", b.Weather.Conditions, b.Weather.Error}}
+
+	if b.Markets.Error != "" {
+		rows = append(rows, []string{"markets", "", "", "", "", b.Markets.Error})
+	}
+	for _, quote := range b.Markets.Quotes {
+		rows = append(rows, []string{"markets", quote.Symbol, output.FormatFloat(quote.Price), output.FormatFloat(quote.Change),
+			output.FormatFloat(quote.ChangePercent) + "%", quote.Error})
+	}
+
+	if b.Treasury.Error != "" {
+		rows = append(rows, []string{"treasury", "", "", "", "", b.Treasury.Error})
+	}
+	for _, spread := range b.Treasury.Spreads {
+		rows = append(rows, []string{"treasury", spread.Name, output.FormatFloat(spread.Value), "", b.Treasury.Date, ""})
+	}
+
+	for _, indicator := range b.Economy {
+		rows = append(rows, []string{"economy", indicator.Name, output.FormatFloat(indicator.Value),
+			output.FormatOptional(indicator.Change), indicator.Period, indicator.Error})
+	}
+
+	for _, league := range b.Sports {
+		if league.Error != "" {
+			rows = append(rows, []string{"sports", league.League, "", "", "", league.Error})
+		}
+		for _, game := range league.Games {
+			rows = append(rows, []string{"sports", league.League + " " + game.ShortName, gameScore(game), "", game.Date, ""})
+		}
+	}
+
+	return rows
+}
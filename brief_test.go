@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"polyapi/bls"
+	"polyapi/espn"
+	"polyapi/fred"
+	"polyapi/stocks"
+	"polyapi/store"
+	"polyapi/treasury"
+)
+
+func TestBriefSectionsDegradeOnTheirOwn(t *testing.T) {
+	t.Setenv("ALPHAVANTAGE_API_KEY", "test-key")
+
+	// Alpha Vantage, FRED and ESPN answer, while the Treasury and BLS APIs are down
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/query":
+			http.ServeFile(w, r, filepath.Join("stocks", "testdata", "global_quote.json"))
+		case strings.HasPrefix(r.URL.Path, "/fred/"):
+			http.ServeFile(w, r, filepath.Join("fred", "testdata", "fedfunds.json"))
+		case r.URL.Path == "/apis/site/v2/sports/football/nfl/scoreboard":
+			http.ServeFile(w, r, filepath.Join("espn", "testdata", "nfl_scoreboard.json"))
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := s.SaveTicker("AAPL", store.Company{Name: "Apple Inc"}, 226.84); err != nil {
+		t.Fatal(err)
+	}
+
+	stocksClient := stocks.NewClient("test-key")
+	stocksClient.HTTPClient, stocksClient.BaseURL = server.Client(), server.URL
+	treasuryClient := treasury.NewClient()
+	treasuryClient.HTTPClient, treasuryClient.BaseURL, treasuryClient.YieldCurveBaseURL = server.Client(), server.URL, server.URL
+	blsClient := bls.NewClient("")
+	blsClient.HTTPClient, blsClient.BaseURL = server.Client(), server.URL
+	fredClient := fred.NewClient("test-key")
+	fredClient.HTTPClient, fredClient.BaseURL = server.Client(), server.URL
+	espnClient := espn.NewClient()
+	espnClient.HTTPClient, espnClient.BaseURL = server.Client(), server.URL
+
+	a := &app{
+		store:    s,
+		stocks:   stocks.NewProvider(stocksClient, s),
+		treasury: treasury.NewProvider(treasuryClient, s),
+		bls:      bls.NewProvider(blsClient, s),
+		fred:     fred.NewProvider(fredClient, s),
+		espn:     espn.NewProvider(espnClient),
+	}
+	leagues, err := parseLeagues("nfl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	briefing := a.brief(context.Background(), "", leagues)
+
+	if briefing.Weather.Error == "" {
+		t.Errorf("weather without a saved address = %+v, want an error", briefing.Weather)
+	}
+	if briefing.Treasury.Error == "" {
+		t.Errorf("treasury = %+v, want an error", briefing.Treasury)
+	}
+	for _, indicator := range briefing.Economy[:2] {
+		if indicator.Error == "" {
+			t.Errorf("%s = %+v, want an error", indicator.Name, indicator)
+		}
+	}
+
+	if quotes := briefing.Markets.Quotes; len(quotes) != 1 || quotes[0].Error != "" || quotes[0].Price != 227.18 {
+		t.Errorf("markets = %+v, want the AAPL quote", briefing.Markets)
+	}
+	if fedFunds := briefing.Economy[2]; fedFunds.Error != "" || fedFunds.Period == "" {
+		t.Errorf("federal funds rate = %+v", fedFunds)
+	}
+	if len(briefing.Sports) != 1 || briefing.Sports[0].Error != "" {
+		t.Errorf("sports = %+v", briefing.Sports)
+	}
+
+	// The quotes are added to the price history
+	history, err := s.PriceQuotes("AAPL", time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Price != 227.18 {
+		t.Errorf("price history = %+v", history)
+	}
+}
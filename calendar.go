@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"polyapi/fred"
+	"polyapi/httpx"
+	"polyapi/store"
+)
+
+// calendarCommand prints the release calendar of the watchlist series. With --refresh,
+// the series released since they were last fetched are fetched again first.
+func (a *app) calendarCommand(ctx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("calendar", flag.ContinueOnError)
+	days := flags.Int("days", 7, "number of days of upcoming releases to show")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *days < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+
+	calendar, err := a.fred.Calendar(ctx, *days)
+	if err != nil {
+		return err
+	}
+
+	// --refresh is a global option, so it's on for the whole command
+	if cacheMode == httpx.CacheRefresh && len(calendar.Stale) > 0 {
+		if err := a.refreshStale(ctx, calendar); err != nil {
+			return err
+		}
+		if calendar, err = a.fred.Calendar(ctx, *days); err != nil {
+			return err
+		}
+	}
+
+	if err := render(calendar); err != nil {
+		return err
+	}
+	if len(calendar.Stale) > 0 && outputFormat == "table" {
+		fmt.Println("Run `polyapi calendar --refresh` to fetch them.")
+	}
+	return nil
+}
+
+// refreshStale fetches the series released since they were last fetched, skipping
+// the response cache so the new observations are seen.
+func (a *app) refreshStale(ctx context.Context, calendar fred.Calendar) error {
+
+	ctx = httpx.WithCacheMode(ctx, httpx.CacheRefresh)
+
+	stale := map[string]bool{}
+	for _, entry := range calendar.Stale {
+		stale[entry.Source+" "+entry.SeriesID] = true
+	}
+
+	watchlist, err := a.store.Watchlist("")
+	if err != nil {
+		return err
+	}
+
+	var blsSeries []store.Series
+	for _, series := range watchlist {
+		if !stale[series.Source+" "+series.SeriesID] {
+			continue
+		}
+		if series.Source == "bls" {
+			blsSeries = append(blsSeries, series)
+			continue
+		}
+		info := fred.SeriesInfo{ID: series.SeriesID, Title: series.Label, Frequency: series.Frequency, Units: series.Units}
+		if _, err := a.fred.Series(ctx, info); err != nil {
+			log.Printf("Error refreshing FRED series %s: %v", series.SeriesID, err)
+		}
+	}
+
+	if len(blsSeries) > 0 {
+		if _, err := a.bls.Data(ctx, blsSeries); err != nil {
+			log.Printf("Error refreshing BLS series: %v", err)
+		}
+	}
+
+	return nil
+}
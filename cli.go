@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"polyapi/httpx"
+	"polyapi/output"
+	"polyapi/provider"
+)
+
+// printUsage prints the command line usage with the registered providers.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: polyapi [--output table|json|csv] [--refresh|--no-cache] [command] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run without a command to start the interactive terminal UI.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, p := range provider.All() {
+		fmt.Fprintf(w, "  %-10s %s\n", p.Name(), p.Description())
+	}
+	fmt.Fprintf(w, "  %-10s %s\n", "series", "BLS and FRED watchlist (series add bls|fred ID [--label L], series remove bls|fred ID, series list)")
+	fmt.Fprintf(w, "  %-10s %s\n", "brief", "morning briefing of weather, markets, yields, economy and games (brief [--address ADDRESS] [--leagues nfl,nba])")
+	fmt.Fprintf(w, "  %-10s %s\n", "local", "BLS unemployment rates and CPI for the state and metro area of an address (local --address ADDRESS)")
+	fmt.Fprintf(w, "  %-10s %s\n", "calendar", "upcoming FRED releases of the watchlist series (calendar [--days N], --refresh fetches released series)")
+	fmt.Fprintf(w, "  %-10s %s\n", "serve", "serve every provider as JSON over HTTP until interrupted (serve [--addr :8080] [--update 15m])")
+	fmt.Fprintf(w, "  %-10s %s\n", "db", "database migrations (db migrate, db status, db rollback [--steps N])")
+	fmt.Fprintf(w, "  %-10s %s\n", "help", "print this help")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Options:")
+	fmt.Fprintln(w, "  -o, --output FORMAT   table (default), json or csv")
+	fmt.Fprintln(w, "  --refresh             fetch from the APIs and update the response cache")
+	fmt.Fprintln(w, "  --no-cache            don't read or write the response cache")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Options may be given anywhere on the command line.")
+}
+
+// parseGlobalFlags removes the global options from anywhere in args, so they can be
+// given before the command or among the command's own arguments, and returns the rest.
+// Arguments after -- are passed on as they are, with the --, which the commands
+// then take as the end of their options.
+func parseGlobalFlags(args []string) ([]string, error) {
+
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Words such as a ticker symbol or a search term aren't options
+		if arg == "--" {
+			return append(rest, args[i:]...), nil
+		}
+		if !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch strings.TrimLeft(name, "-") {
+		case "no-cache":
+			cacheMode = httpx.CacheOff
+			continue
+		case "refresh":
+			cacheMode = httpx.CacheRefresh
+			continue
+		case "o", "output":
+		default:
+			rest = append(rest, arg)
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag needs an argument: %s", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		if !output.ValidFormat(value) {
+			return nil, fmt.Errorf("unknown output format: %s (use table, json or csv)", value)
+		}
+		outputFormat = value
+	}
+
+	return rest, nil
+}
+
+// runCommand runs a single non-interactive command, e.g. `polyapi quote AAPL`,
+// with the provider registered under the command name.
+func (a *app) runCommand(ctx context.Context, args []string) error {
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage(os.Stdout)
+		return nil
+	case "series":
+		return a.seriesCommand(ctx, args[1:])
+	case "calendar":
+		return a.calendarCommand(ctx, args[1:])
+	case "brief":
+		return a.briefCommand(ctx, args[1:])
+	case "local":
+		return a.localCommand(ctx, args[1:])
+	case "serve":
+		return a.serveCommand(ctx, args[1:])
+	case "salesforce":
+		args[0] = "sf"
+	}
+
+	p, ok := provider.Lookup(args[0])
+	if !ok {
+		printUsage(os.Stderr)
+		return fmt.Errorf("unknown command: %s", args[0])
+	}
+
+	if err := provider.CheckConfig(p); err != nil {
+		return err
+	}
+
+	result, err := p.Fetch(ctx, args[1:])
+	if err != nil {
+		return err
+	}
+
+	return render(result)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"polyapi/httpx"
+	"polyapi/stocks"
+	"polyapi/store"
+)
+
+func TestParseGlobalFlags(t *testing.T) {
+	defer func(mode httpx.CacheMode, format string) {
+		cacheMode, outputFormat = mode, format
+	}(cacheMode, outputFormat)
+
+	tests := []struct {
+		args   []string
+		want   []string
+		format string
+		mode   httpx.CacheMode
+	}{
+		{[]string{"-o", "json", "quote", "AAPL"}, []string{"quote", "AAPL"}, "json", httpx.CacheDefault},
+		{[]string{"quote", "AAPL", "--output=csv", "--refresh"}, []string{"quote", "AAPL"}, "csv", httpx.CacheRefresh},
+		// Words that are also option names are passed on to the provider
+		{[]string{"fred", "search", "o", "refresh", "output", "no-cache"}, []string{"fred", "search", "o", "refresh", "output", "no-cache"}, "table", httpx.CacheDefault},
+		{[]string{"fred", "search", "--", "-o", "--no-cache"}, []string{"fred", "search", "--", "-o", "--no-cache"}, "table", httpx.CacheDefault},
+		{[]string{"quote", "--", "AAPL"}, []string{"quote", "--", "AAPL"}, "table", httpx.CacheDefault},
+	}
+
+	for _, tt := range tests {
+		cacheMode, outputFormat = httpx.CacheDefault, "table"
+		got, err := parseGlobalFlags(tt.args)
+		if err != nil {
+			t.Errorf("%q: %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) || outputFormat != tt.format || cacheMode != tt.mode {
+			t.Errorf("%q: got %q, format %s, cache mode %v, want %q, %s, %v", tt.args, got, outputFormat, cacheMode, tt.want, tt.format, tt.mode)
+		}
+	}
+
+	if _, err := parseGlobalFlags([]string{"quote", "-o"}); err == nil {
+		t.Error("no error for -o without a format")
+	}
+}
+
+func TestQuoteAfterDoubleDash(t *testing.T) {
+	fixtures := map[string]string{"GLOBAL_QUOTE": "global_quote.json", "OVERVIEW": "overview.json"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if symbol := r.URL.Query().Get("symbol"); symbol != "AAPL" {
+			t.Errorf("quoted %q, want AAPL", symbol)
+		}
+		http.ServeFile(w, r, filepath.Join("stocks", "testdata", fixtures[r.URL.Query().Get("function")]))
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	client := stocks.NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := stocks.NewProvider(client, s)
+
+	args, err := parseGlobalFlags([]string{"quote", "--", "AAPL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := p.Fetch(context.Background(), args[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quotes := result.(stocks.Quotes); len(quotes) != 1 || quotes[0].Symbol != "AAPL" {
+		t.Errorf("Fetch() = %+v, want the AAPL quote", quotes)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"polyapi/store"
+)
+
+// Migration is a schema migration and whether it has been applied.
+type Migration struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	Applied   bool   `json:"applied"`
+	AppliedAt string `json:"applied_at,omitempty"`
+}
+
+// Migrations is the migration status of the database.
+type Migrations []Migration
+
+// PrintTable prints each migration with when it was applied.
+func (m Migrations) PrintTable(w io.Writer) {
+	fmt.Fprintln(w)
+	for _, migration := range m {
+		appliedAt := "pending"
+		if migration.Applied {
+			appliedAt = migration.AppliedAt
+		}
+		fmt.Fprintf(w, "  %3d  %-45s %s\n", migration.Version, migration.Name, appliedAt)
+	}
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the migration columns.
+func (m Migrations) CSVHeader() []string {
+	return []string{"version", "name", "applied", "applied_at"}
+}
+
+// CSVRows returns one row per migration.
+func (m Migrations) CSVRows() [][]string {
+	var rows [][]string
+	for _, migration := range m {
+		rows = append(rows, []string{strconv.Itoa(migration.Version), migration.Name, strconv.FormatBool(migration.Applied), migration.AppliedAt})
+	}
+	return rows
+}
+
+// migrationStatus returns the migration status of the database.
+func migrationStatus(s *store.Store) (Migrations, error) {
+
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var result Migrations
+	for _, status := range statuses {
+		migration := Migration{Version: status.Version, Name: status.Name, Applied: status.Applied}
+		if status.Applied {
+			migration.AppliedAt = status.AppliedAt.Local().Format("2006-01-02 03:04 PM")
+		}
+		result = append(result, migration)
+	}
+
+	return result, nil
+}
+
+// dbCommand migrates the database, rolls it back or prints its migration status.
+// Other commands apply pending migrations when they open the database.
+func dbCommand(args []string) error {
+
+	if len(args) == 0 {
+		return fmt.Errorf("db requires a subcommand: migrate, status or rollback")
+	}
+
+	flags := flag.NewFlagSet("db "+args[0], flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to roll back")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	s, err := store.OpenWithoutMigrating(store.DefaultPath)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	switch args[0] {
+	case "migrate":
+		applied, err := s.Migrate()
+		for _, migration := range applied {
+			fmt.Printf("Applied migration %d: %s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("The database is up to date.")
+		}
+		return err
+	case "rollback":
+		rolledBack, err := s.Rollback(*steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("Rolled back migration %d: %s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(rolledBack) == 0 {
+			fmt.Println("No migrations to roll back.")
+		}
+		return err
+	case "status":
+		migrations, err := migrationStatus(s)
+		if err != nil {
+			return err
+		}
+		return render(migrations)
+	default:
+		return fmt.Errorf("unknown db subcommand: %s", args[0])
+	}
+}
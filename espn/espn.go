@@ -0,0 +1,178 @@
+// Package espn gets schedules and scores from the ESPN scoreboard API.
+// See https://gist.github.com/akeaswaran/b48b02f1c94f873c6655e7129910fc3b
+package espn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"polyapi/httpx"
+)
+
+type Event struct {
+	ID           string `json:"id"`
+	Date         string `json:"date"`
+	Name         string `json:"name"`
+	ShortName    string `json:"shortName"`
+	Competitions []struct {
+		Competitors []struct {
+			Team struct {
+				ID          string `json:"id"`
+				Name        string `json:"name"`
+				DisplayName string `json:"displayName"`
+				Links       []struct {
+					Href string `json:"href"`
+					Text string `json:"text"`
+				} `json:"links"`
+			} `json:"team"`
+			HomeAway string `json:"homeAway"`
+			Links    []struct {
+				Href string `json:"href"`
+				Text string `json:"text"`
+			} `json:"links"`
+			Score   string `json:"score"`
+			Records []struct {
+				Name    string `json:"name"`
+				Summary string `json:"summary"`
+			} `json:"records"`
+		} `json:"competitors"`
+		Broadcasts []struct {
+			Market string   `json:"market"`
+			Names  []string `json:"names"`
+		} `json:"broadcasts"`
+		Headlines []Headline `json:"headlines"`
+	} `json:"competitions"`
+	Status struct {
+		DisplayClock string `json:"displayClock"`
+		Period       int    `json:"period"`
+		Type         struct {
+			Detail      string `json:"detail"`
+			ShortDetail string `json:"shortDetail"`
+			Description string `json:"description"`
+			State       string `json:"state"`
+			Completed   bool   `json:"completed"`
+		} `json:"type"`
+	} `json:"status"`
+	Links []struct {
+		Href string `json:"href"`
+		Text string `json:"text"`
+	} `json:"links"`
+	Weather struct {
+		DisplayValue string `json:"displayValue"`
+		Temperature  int    `json:"temperature"`
+		Link         struct {
+			Href string `json:"href"`
+			Text string `json:"text"`
+		} `json:"link"`
+	} `json:"weather"`
+}
+
+type Headline struct {
+	Type          string `json:"type"`
+	Description   string `json:"description"`
+	ShortLinkText string `json:"shortLinkText"`
+	Video         []struct {
+		Links struct {
+			Web struct {
+				Href string `json:"href"`
+			} `json:"web"`
+		} `json:"links"`
+	} `json:"video"`
+}
+
+// ScoreboardResponse is the ESPN scoreboard for a league.
+type ScoreboardResponse struct {
+	Events []Event `json:"events"`
+}
+
+// Leagues maps each league to its ESPN scoreboard path.
+var Leagues = map[string]string{
+	"NFL":       "/apis/site/v2/sports/football/nfl/scoreboard",
+	"College":   "/apis/site/v2/sports/football/college-football/scoreboard?groups=80",
+	"College25": "/apis/site/v2/sports/football/college-football/scoreboard",
+	"MLB":       "/apis/site/v2/sports/baseball/mlb/scoreboard",
+	"EPL":       "/apis/site/v2/sports/soccer/eng.1/scoreboard",
+	"MLS":       "/apis/site/v2/sports/soccer/usa.1/scoreboard",
+	"NHL":       "/apis/site/v2/sports/hockey/nhl/scoreboard",
+	"WNBA":      "/apis/site/v2/sports/basketball/wnba/scoreboard",
+	"NBA":       "/apis/site/v2/sports/basketball/nba/scoreboard",
+	"CollegeBB": "/apis/site/v2/sports/basketball/mens-college-basketball/scoreboard",
+}
+
+// LeagueNames maps the league names accepted on the command line to league keys.
+var LeagueNames = map[string]string{
+	"nfl":       "NFL",
+	"college":   "College",
+	"college25": "College25",
+	"mlb":       "MLB",
+	"epl":       "EPL",
+	"mls":       "MLS",
+	"nhl":       "NHL",
+	"wnba":      "WNBA",
+	"nba":       "NBA",
+	"collegebb": "CollegeBB",
+}
+
+// ParseLeague returns the league key for a league name such as "nfl" or "NFL".
+func ParseLeague(name string) (string, error) {
+	league, ok := LeagueNames[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("unknown league: %s", name)
+	}
+	return league, nil
+}
+
+// DefaultBaseURL is the base URL of the ESPN site API.
+const DefaultBaseURL = "https://site.api.espn.com"
+
+// Client calls the ESPN scoreboard API.
+type Client struct {
+	HTTPClient *http.Client
+	BaseURL    string
+}
+
+// NewClient returns an ESPN client.
+func NewClient() *Client {
+	return &Client{HTTPClient: httpx.DefaultClient, BaseURL: DefaultBaseURL}
+}
+
+// Schedule fetches the ESPN scoreboard for a league.
+func (c *Client) Schedule(ctx context.Context, league string) (Schedule, error) {
+
+	schedule := Schedule{League: league}
+
+	path, ok := Leagues[league]
+	if !ok {
+		return schedule, fmt.Errorf("unknown league: %s", league)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+path, nil)
+	if err != nil {
+		return schedule, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return schedule, err
+	}
+	if err := httpx.CheckResponse(resp); err != nil {
+		return schedule, err
+	}
+
+	defer resp.Body.Close()
+
+	var data ScoreboardResponse
+
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return schedule, err
+	}
+
+	for _, event := range data.Events {
+		schedule.Games = append(schedule.Games, newGame(league, event))
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,60 @@
+package espn
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestSchedule(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/site/v2/sports/football/nfl/scoreboard" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", "nfl_scoreboard.json"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+
+	schedule, err := client.Schedule(context.Background(), "NFL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.League != "NFL" || len(schedule.Games) != 2 {
+		t.Fatalf("schedule = %+v", schedule)
+	}
+
+	final := schedule.Games[0]
+	if final.ShortName != "BAL @ KC" || final.Date != "2024-09-06T00:20Z" || final.Status != "Final" || final.State != "post" {
+		t.Errorf("game = %+v", final)
+	}
+	if len(final.Competitors) != 2 || final.Competitors[0].Score != "27" || len(final.Competitors[0].Links) != 2 {
+		t.Errorf("competitors = %+v", final.Competitors)
+	}
+	if final.Headline != "Chiefs hold off Ravens in opener" || final.VideoURL == "" {
+		t.Errorf("headline = %q, video = %q", final.Headline, final.VideoURL)
+	}
+	if final.Link == nil || final.Link.Text != "Gamecast" || final.WeatherLink == nil || final.Temperature != 79 {
+		t.Errorf("links = %+v, %+v", final.Link, final.WeatherLink)
+	}
+	if len(final.Broadcasts) != 1 || final.Broadcasts[0] != "NBC, Peacock" {
+		t.Errorf("broadcasts = %v", final.Broadcasts)
+	}
+
+	// Games that haven't started have no headline or weather
+	scheduled := schedule.Games[1]
+	if scheduled.State != "pre" || scheduled.Headline != "" || scheduled.WeatherLink != nil {
+		t.Errorf("game = %+v", scheduled)
+	}
+}
+
+func TestScheduleUnknownLeague(t *testing.T) {
+	if _, err := NewClient().Schedule(context.Background(), "XFL"); err == nil {
+		t.Error("expected an error for an unknown league")
+	}
+}
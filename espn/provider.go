@@ -0,0 +1,62 @@
+package espn
+
+import (
+	"context"
+	"flag"
+
+	"polyapi/output"
+	"polyapi/provider"
+)
+
+// Provider gets league schedules and scores.
+type Provider struct {
+	Client *Client
+}
+
+// NewProvider returns an ESPN provider.
+func NewProvider(client *Client) *Provider {
+	return &Provider{Client: client}
+}
+
+func (p *Provider) Name() string { return "espn" }
+
+func (p *Provider) Description() string {
+	return "ESPN schedules and scores (nfl, college, college25, mlb, epl, mls, nhl, wnba, nba, collegebb)"
+}
+
+func (p *Provider) RequiredConfig() []string { return nil }
+
+// Fetch returns the schedule for the league given as the first argument,
+// or the links for one game with --event N.
+func (p *Provider) Fetch(ctx context.Context, args []string) (output.Result, error) {
+
+	if len(args) == 0 {
+		return nil, provider.Usagef("espn requires a league, e.g. polyapi espn nfl")
+	}
+
+	league, err := ParseLeague(args[0])
+	if err != nil {
+		return nil, &provider.UsageError{Err: err}
+	}
+
+	flags := flag.NewFlagSet("espn", flag.ContinueOnError)
+	event := flags.Int("event", 0, "print game, team and weather links for this event number")
+	if err := provider.ParseFlags(ctx, flags, args[1:]); err != nil {
+		return nil, err
+	}
+
+	schedule, err := p.Client.Schedule(ctx, league)
+	if err != nil {
+		return nil, err
+	}
+
+	if *event == 0 {
+		return schedule, nil
+	}
+
+	if *event < 1 || *event > len(schedule.Games) {
+		return nil, provider.Usagef("event number out of range: %d", *event)
+	}
+
+	return GameDetails{League: league, Game: schedule.Games[*event-1]}, nil
+}
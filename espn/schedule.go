@@ -0,0 +1,226 @@
+package espn
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Link is a titled link returned by ESPN.
+type Link struct {
+	Text string `json:"text"`
+	Href string `json:"href"`
+}
+
+// TeamScore is a competitor in a game with its score and team links.
+type TeamScore struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	HomeAway    string `json:"home_away"`
+	Score       string `json:"score,omitempty"`
+	Links       []Link `json:"links,omitempty"`
+}
+
+// Game is a single event on an ESPN scoreboard. Date is its start time in UTC,
+// e.g. 2024-09-06T00:20Z.
+type Game struct {
+	Name        string      `json:"name"`
+	Date        string      `json:"date,omitempty"`
+	ShortName   string      `json:"short_name"`
+	Status      string      `json:"status"`
+	State       string      `json:"state"`
+	Broadcasts  []string    `json:"broadcasts,omitempty"`
+	Competitors []TeamScore `json:"competitors"`
+	Headline    string      `json:"headline,omitempty"`
+	VideoURL    string      `json:"video_url,omitempty"`
+	Link        *Link       `json:"link,omitempty"`
+	Weather     string      `json:"weather,omitempty"`
+	Temperature int         `json:"temperature,omitempty"`
+	WeatherLink *Link       `json:"weather_link,omitempty"`
+}
+
+// Schedule is the ESPN scoreboard for a league.
+type Schedule struct {
+	League string `json:"league"`
+	Games  []Game `json:"games"`
+}
+
+// GameDetails is a single game rendered with its game, team and weather links.
+type GameDetails struct {
+	League string `json:"league"`
+	Game
+}
+
+// newGame converts an ESPN scoreboard event to a Game.
+// MLB uses the short status detail since its detail includes the inning.
+func newGame(league string, event Event) Game {
+
+	game := Game{
+		Name:      event.Name,
+		Date:      event.Date,
+		ShortName: event.ShortName,
+		Status:    event.Status.Type.Detail,
+		State:     event.Status.Type.State,
+	}
+	if league == "MLB" {
+		game.Status = event.Status.Type.ShortDetail
+	}
+
+	if len(event.Links) > 0 {
+		game.Link = &Link{Text: event.Links[0].Text, Href: event.Links[0].Href}
+	}
+
+	if event.Weather.DisplayValue != "" || event.Weather.Link.Href != "" {
+		game.Weather = event.Weather.DisplayValue
+		game.Temperature = event.Weather.Temperature
+		game.WeatherLink = &Link{Text: event.Weather.Link.Text, Href: event.Weather.Link.Href}
+	}
+
+	if len(event.Competitions) == 0 {
+		return game
+	}
+	competition := event.Competitions[0]
+
+	for _, broadcast := range competition.Broadcasts {
+		game.Broadcasts = append(game.Broadcasts, strings.Join(broadcast.Names, ", "))
+	}
+
+	for _, competitor := range competition.Competitors {
+		team := TeamScore{
+			Name:        competitor.Team.Name,
+			DisplayName: competitor.Team.DisplayName,
+			HomeAway:    competitor.HomeAway,
+			Score:       competitor.Score,
+		}
+		for _, link := range competitor.Team.Links {
+			team.Links = append(team.Links, Link{Text: link.Text, Href: link.Href})
+		}
+		game.Competitors = append(game.Competitors, team)
+	}
+
+	for _, headline := range competition.Headlines {
+		game.Headline = headline.ShortLinkText
+		if len(headline.Video) > 0 {
+			game.VideoURL = headline.Video[0].Links.Web.Href
+		}
+		break
+	}
+
+	return game
+}
+
+// PrintTable prints the numbered games with scores once they have started.
+func (s Schedule) PrintTable(w io.Writer) {
+
+	fmt.Fprintf(w, "\n%s Schedule:", s.League)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+
+	for i, game := range s.Games {
+		fmt.Fprintf(w, "%d. \n", i+1)
+		fmt.Fprintf(w, "%s\n", game.Name)
+		fmt.Fprintf(w, "%s\n", game.Status)
+
+		if game.State != "post" {
+			for _, broadcast := range game.Broadcasts {
+				fmt.Fprintf(w, "%s\n", broadcast)
+			}
+		}
+		fmt.Fprintln(w)
+
+		if game.State != "pre" {
+			for _, team := range game.Competitors {
+				fmt.Fprintf(w, "%s %s\n", team.Name, team.Score)
+			}
+		}
+
+		fmt.Fprintln(w)
+		if game.Headline != "" {
+			fmt.Fprintln(w, game.Headline)
+			if game.VideoURL != "" {
+				fmt.Fprintln(w, game.VideoURL)
+			}
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the game columns.
+func (s Schedule) CSVHeader() []string {
+	return []string{"league", "name", "short_name", "status", "state", "away_team", "away_score", "home_team", "home_score", "broadcasts", "headline"}
+}
+
+// CSVRows returns one row per game.
+func (s Schedule) CSVRows() [][]string {
+	var rows [][]string
+	for _, game := range s.Games {
+		var home, away TeamScore
+		for _, team := range game.Competitors {
+			if team.HomeAway == "home" {
+				home = team
+			} else {
+				away = team
+			}
+		}
+		rows = append(rows, []string{s.League, game.Name, game.ShortName, game.Status, game.State,
+			away.Name, away.Score, home.Name, home.Score, strings.Join(game.Broadcasts, "; "), game.Headline})
+	}
+	return rows
+}
+
+// PrintTable prints the game, team and weather links.
+func (d GameDetails) PrintTable(w io.Writer) {
+
+	fmt.Fprintln(w)
+
+	if d.Link != nil {
+		fmt.Fprintln(w, d.Link.Text+": ", d.Link.Href)
+	}
+
+	if d.WeatherLink != nil {
+		if d.State == "post" {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "Expected "+d.WeatherLink.Text+": ", d.Weather, strconv.Itoa(d.Temperature)+"°F")
+		}
+
+		fmt.Fprintln(w)
+
+		fmt.Fprintln(w, "More info: "+d.WeatherLink.Href)
+	}
+
+	fmt.Fprintln(w)
+
+	for _, team := range d.Competitors {
+		fmt.Fprintln(w, team.DisplayName)
+		fmt.Fprintln(w)
+
+		// ESPN repeats the Clubhouse link for some teams
+		var repeatCount = 0
+		for _, link := range team.Links {
+			if link.Text == "Clubhouse" {
+				if repeatCount > 0 {
+					continue
+				}
+				repeatCount++
+			}
+			fmt.Fprintln(w, link.Text+": ", link.Href)
+		}
+
+		fmt.Fprintln(w)
+	}
+}
+
+// CSVHeader returns the game columns.
+func (d GameDetails) CSVHeader() []string {
+	return Schedule{}.CSVHeader()
+}
+
+// CSVRows returns the game as a single row.
+func (d GameDetails) CSVRows() [][]string {
+	return Schedule{League: d.League, Games: []Game{d.Game}}.CSVRows()
+}
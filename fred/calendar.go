@@ -0,0 +1,202 @@
+package fred
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"polyapi/output"
+	"polyapi/store"
+)
+
+// CalendarEntry is a release date of a series on the watchlist, with the latest
+// value of the series stored before it.
+type CalendarEntry struct {
+	Date        string   `json:"date"`
+	ReleaseID   int      `json:"release_id"`
+	ReleaseName string   `json:"release_name"`
+	Source      string   `json:"source"`
+	SeriesID    string   `json:"series_id"`
+	Label       string   `json:"label"`
+	LastDate    string   `json:"last_date,omitempty"`
+	LastValue   *float64 `json:"last_value,omitempty"`
+}
+
+// Calendar is the upcoming release dates of the series on the watchlist, and the
+// series with a release since they were last fetched.
+type Calendar struct {
+	Start    string          `json:"start"`
+	End      string          `json:"end"`
+	Releases []CalendarEntry `json:"releases"`
+	Stale    []CalendarEntry `json:"stale,omitempty"`
+}
+
+// ReleaseLookbackDays is how far back releases are checked for series that are out of date.
+const ReleaseLookbackDays = 31
+
+// Calendar gets the release dates of the watchlist series from today for the given
+// number of days. Series released since they were last fetched are listed as stale.
+// BLS series are included when they have a FRED release ID.
+func (p *Provider) Calendar(ctx context.Context, days int) (Calendar, error) {
+	now := time.Now()
+	return p.calendar(ctx, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), days)
+}
+
+func (p *Provider) calendar(ctx context.Context, today time.Time, days int) (Calendar, error) {
+
+	calendar := Calendar{Start: today.Format("2006-01-02"), End: today.AddDate(0, 0, days-1).Format("2006-01-02")}
+
+	watchlist, err := p.Store.Watchlist("")
+	if err != nil {
+		return calendar, err
+	}
+
+	byRelease := map[int][]store.Series{}
+	for _, series := range watchlist {
+		// FRED series added before they had a release are looked up once
+		if series.ReleaseID == 0 && series.Source == source {
+			release, err := p.Client.SeriesRelease(ctx, series.SeriesID)
+			if err != nil {
+				log.Printf("Error getting the release of FRED series %s: %v", series.SeriesID, err)
+				continue
+			}
+			series.ReleaseID = release.ID
+			if err := p.Store.SetSeriesRelease(source, series.SeriesID, release.ID); err != nil {
+				return calendar, err
+			}
+		}
+		if series.ReleaseID != 0 {
+			byRelease[series.ReleaseID] = append(byRelease[series.ReleaseID], series)
+		}
+	}
+
+	dates, err := p.Client.ReleaseDates(ctx, today.AddDate(0, 0, -ReleaseLookbackDays).Format("2006-01-02"), calendar.End)
+	if err != nil {
+		return calendar, err
+	}
+
+	// The latest release of each series before today, to find the series that are out of date
+	released := map[string]ReleaseDate{}
+
+	for _, date := range dates {
+		for _, series := range byRelease[date.ReleaseID] {
+			if date.Date < calendar.Start {
+				released[series.Source+" "+series.SeriesID] = date
+				continue
+			}
+			entry, err := p.calendarEntry(date, series)
+			if err != nil {
+				return calendar, err
+			}
+			calendar.Releases = append(calendar.Releases, entry)
+		}
+	}
+
+	for _, series := range watchlist {
+		date, ok := released[series.Source+" "+series.SeriesID]
+		if !ok {
+			continue
+		}
+		releaseDate, err := time.ParseInLocation("2006-01-02", date.Date, time.Local)
+		if err != nil {
+			return calendar, err
+		}
+		// A series fetched on its release day may have been fetched before the release
+		if !series.FetchedAt.Before(releaseDate.AddDate(0, 0, 1)) {
+			continue
+		}
+		entry, err := p.calendarEntry(date, series)
+		if err != nil {
+			return calendar, err
+		}
+		calendar.Stale = append(calendar.Stale, entry)
+	}
+
+	return calendar, nil
+}
+
+// calendarEntry returns the entry of a series released on date with its latest stored value.
+func (p *Provider) calendarEntry(date ReleaseDate, series store.Series) (CalendarEntry, error) {
+
+	entry := CalendarEntry{
+		Date:        date.Date,
+		ReleaseID:   date.ReleaseID,
+		ReleaseName: date.ReleaseName,
+		Source:      series.Source,
+		SeriesID:    series.SeriesID,
+		Label:       series.Label,
+	}
+
+	latest, ok, err := p.Store.LatestObservation(series.Source, series.SeriesID)
+	if err != nil {
+		return entry, err
+	}
+	if ok {
+		entry.LastDate = latest.Date
+		entry.LastValue = &latest.Value
+	}
+
+	return entry, nil
+}
+
+// PrintTable prints the upcoming releases, each followed by its series and their
+// latest values, then the series released since they were last fetched.
+func (c Calendar) PrintTable(w io.Writer) {
+
+	fmt.Fprintf(w, "\nRelease calendar from %s to %s\n\n", c.Start, c.End)
+
+	if len(c.Releases) == 0 {
+		fmt.Fprintln(w, "No releases of the watchlist series are scheduled.")
+	}
+	for i, entry := range c.Releases {
+		if i == 0 || entry.Date != c.Releases[i-1].Date || entry.ReleaseID != c.Releases[i-1].ReleaseID {
+			fmt.Fprintf(w, "%s  %s\n", entry.Date, entry.ReleaseName)
+		}
+		fmt.Fprintf(w, "  %-5s %-20s %s: %s\n", entry.Source, entry.SeriesID, entry.Label, entry.lastValue())
+	}
+
+	if len(c.Stale) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Released since last fetched:")
+		for _, entry := range c.Stale {
+			fmt.Fprintf(w, "  %-5s %-20s %s: released %s, last value %s\n", entry.Source, entry.SeriesID, entry.Label, entry.Date, entry.lastValue())
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// lastValue formats the latest stored value with its date.
+func (e CalendarEntry) lastValue() string {
+	if e.LastValue == nil {
+		return "none stored"
+	}
+	return fmt.Sprintf("%s (%s)", output.FormatFloat(*e.LastValue), e.LastDate)
+}
+
+// CSVHeader returns the calendar columns.
+func (c Calendar) CSVHeader() []string {
+	return []string{"date", "release_id", "release_name", "source", "series_id", "label", "last_date", "last_value", "stale"}
+}
+
+// CSVRows returns one row per upcoming release of a series, then one per stale series.
+func (c Calendar) CSVRows() [][]string {
+	var rows [][]string
+	for _, entry := range c.Releases {
+		rows = append(rows, entry.csvRow(false))
+	}
+	for _, entry := range c.Stale {
+		rows = append(rows, entry.csvRow(true))
+	}
+	return rows
+}
+
+func (e CalendarEntry) csvRow(stale bool) []string {
+	value := ""
+	if e.LastValue != nil {
+		value = output.FormatFloat(*e.LastValue)
+	}
+	return []string{e.Date, strconv.Itoa(e.ReleaseID), e.ReleaseName, e.Source, e.SeriesID, e.Label, e.LastDate, value, strconv.FormatBool(stale)}
+}
@@ -0,0 +1,179 @@
+package fred
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SeriesInfo describes a FRED series.
+type SeriesInfo struct {
+	ID                 string `json:"id"`
+	Title              string `json:"title"`
+	Frequency          string `json:"frequency"`
+	Units              string `json:"units"`
+	SeasonalAdjustment string `json:"seasonal_adjustment,omitempty"`
+	LastUpdated        string `json:"last_updated,omitempty"`
+	ObservationStart   string `json:"observation_start,omitempty"`
+	ObservationEnd     string `json:"observation_end,omitempty"`
+}
+
+// SeriesResponse is the response of the FRED series and series search endpoints.
+type SeriesResponse struct {
+	Series []SeriesInfo `json:"seriess"`
+}
+
+// Category is a FRED category of series. The root category has ID 0.
+type Category struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	ParentID int    `json:"parent_id"`
+}
+
+// CategoryResponse is the response of the FRED category endpoints.
+type CategoryResponse struct {
+	Categories []Category `json:"categories"`
+}
+
+// SeriesInfo gets the title, frequency, units and seasonal adjustment of a FRED series.
+func (c *Client) SeriesInfo(ctx context.Context, seriesID string) (SeriesInfo, error) {
+
+	var data SeriesResponse
+	if err := c.get(ctx, "/fred/series", url.Values{"series_id": {seriesID}}, &data); err != nil {
+		return SeriesInfo{}, err
+	}
+	if len(data.Series) == 0 {
+		return SeriesInfo{}, fmt.Errorf("FRED series not found: %s", seriesID)
+	}
+
+	return data.Series[0], nil
+}
+
+// Search finds up to limit FRED series matching the words in text, most popular first.
+func (c *Client) Search(ctx context.Context, text string, limit int) ([]SeriesInfo, error) {
+
+	params := url.Values{
+		"search_text": {text},
+		"limit":       {strconv.Itoa(limit)},
+		"order_by":    {"popularity"},
+		"sort_order":  {"desc"},
+	}
+
+	var data SeriesResponse
+	if err := c.get(ctx, "/fred/series/search", params, &data); err != nil {
+		return nil, err
+	}
+
+	return data.Series, nil
+}
+
+// Category gets a FRED category with its subcategories and up to limit of its series, most popular first.
+func (c *Client) Category(ctx context.Context, categoryID, limit int) (CategoryListing, error) {
+
+	var listing CategoryListing
+
+	var category CategoryResponse
+	if err := c.get(ctx, "/fred/category", url.Values{"category_id": {strconv.Itoa(categoryID)}}, &category); err != nil {
+		return listing, err
+	}
+	if len(category.Categories) == 0 {
+		return listing, fmt.Errorf("FRED category not found: %d", categoryID)
+	}
+	listing.Category = category.Categories[0]
+	listing.Title = listing.Category.Name
+
+	var children CategoryResponse
+	if err := c.get(ctx, "/fred/category/children", url.Values{"category_id": {strconv.Itoa(categoryID)}}, &children); err != nil {
+		return listing, err
+	}
+	listing.Children = children.Categories
+
+	params := url.Values{
+		"category_id": {strconv.Itoa(categoryID)},
+		"limit":       {strconv.Itoa(limit)},
+		"order_by":    {"popularity"},
+		"sort_order":  {"desc"},
+	}
+	var series SeriesResponse
+	if err := c.get(ctx, "/fred/category/series", params, &series); err != nil {
+		return listing, err
+	}
+	listing.Series = series.Series
+
+	return listing, nil
+}
+
+// SeriesList is a numbered list of FRED series, e.g. search results.
+// Added is the ID of the series added to the watchlist from the list, if any.
+type SeriesList struct {
+	Title  string       `json:"title"`
+	Series []SeriesInfo `json:"series"`
+	Added  string       `json:"added,omitempty"`
+}
+
+// PrintTable prints each series with its units, frequency, seasonal adjustment and last update.
+func (l SeriesList) PrintTable(w io.Writer) {
+
+	fmt.Fprintf(w, "\n%s:\n\n", l.Title)
+
+	if len(l.Series) == 0 {
+		fmt.Fprintln(w, "No series found")
+	}
+
+	for i, series := range l.Series {
+		fmt.Fprintf(w, "%d. %s (%s)\n", i+1, series.Title, series.ID)
+		// FRED's last updated time looks like "2024-08-02 07:48:02-05"
+		lastUpdated, _, _ := strings.Cut(series.LastUpdated, " ")
+		fmt.Fprintf(w, "   %s | %s | %s | Updated %s\n", series.Units, series.Frequency, series.SeasonalAdjustment, lastUpdated)
+	}
+
+	if l.Added != "" {
+		fmt.Fprintf(w, "\nAdded %s to the watchlist\n", l.Added)
+	}
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the series columns.
+func (l SeriesList) CSVHeader() []string {
+	return []string{"id", "title", "units", "frequency", "seasonal_adjustment", "last_updated", "observation_start", "observation_end"}
+}
+
+// CSVRows returns one row per series.
+func (l SeriesList) CSVRows() [][]string {
+	var rows [][]string
+	for _, series := range l.Series {
+		rows = append(rows, []string{series.ID, series.Title, series.Units, series.Frequency, series.SeasonalAdjustment,
+			series.LastUpdated, series.ObservationStart, series.ObservationEnd})
+	}
+	return rows
+}
+
+// CategoryListing is a FRED category with its numbered subcategories and series.
+type CategoryListing struct {
+	Category Category   `json:"category"`
+	Children []Category `json:"children"`
+	SeriesList
+}
+
+// PrintTable prints the subcategories with their IDs, then the category's series.
+func (l CategoryListing) PrintTable(w io.Writer) {
+
+	fmt.Fprintf(w, "\n%s (category %d)\n", l.Category.Name, l.Category.ID)
+
+	if len(l.Children) > 0 {
+		fmt.Fprintln(w, "\nSubcategories:")
+		fmt.Fprintln(w)
+		for i, child := range l.Children {
+			fmt.Fprintf(w, "%d. %s (category %d)\n", i+1, child.Name, child.ID)
+		}
+	}
+
+	if len(l.Series) > 0 || len(l.Children) == 0 {
+		l.SeriesList.PrintTable(w)
+	} else {
+		fmt.Fprintln(w)
+	}
+}
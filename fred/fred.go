@@ -0,0 +1,190 @@
+// Package fred gets economic data like the federal funds rate from the
+// Federal Reserve Bank of St. Louis FRED API.
+package fred
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"polyapi/httpx"
+)
+
+type FredResponse struct {
+	Observations []Observation `json:"observations"`
+}
+
+// Observation is the value of a FRED series on a date. Missing values are ".".
+type Observation struct {
+	Date  string `json:"date"`
+	Value string `json:"value"`
+}
+
+// Client calls the FRED API.
+// Get an API key at https://fred.stlouisfed.org/docs/api/api_key.html
+type Client struct {
+	APIKey     string
+	HTTPClient *http.Client
+	BaseURL    string
+}
+
+// DefaultBaseURL is the base URL of the FRED API.
+const DefaultBaseURL = "https://api.stlouisfed.org"
+
+// NewClient returns a FRED client using apiKey.
+func NewClient(apiKey string) *Client {
+	return &Client{APIKey: apiKey, HTTPClient: httpx.DefaultClient, BaseURL: DefaultBaseURL}
+}
+
+// newChange calculates the change from an earlier observation to the latest one.
+// The percent change is 0 when the earlier value is 0.
+func newChange(latest, earlier observation) *Change {
+	change := &Change{
+		Date:   earlier.Observation.Date,
+		Value:  earlier.Observation.Value,
+		Change: latest.value - earlier.value,
+	}
+	if earlier.value != 0 {
+		change.PercentChange = change.Change / earlier.value * 100
+	}
+	return change
+}
+
+// inPercent reports whether a series' units are a percent, e.g. "Percent" or
+// "Percent Change from Year Ago", rather than a level such as dollars or an index.
+func inPercent(units string) bool {
+	return strings.HasPrefix(units, "Percent")
+}
+
+// get calls a FRED API endpoint with params and decodes the JSON response into v.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v any) error {
+
+	if c.APIKey == "" {
+		return fmt.Errorf("FRED_API_KEY environment variable is not set")
+	}
+
+	params.Set("api_key", c.APIKey)
+	params.Set("file_type", "json")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if err := httpx.CheckResponse(resp); err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Observations gets the observations of a FRED series between startDate and endDate, oldest first.
+func (c *Client) Observations(ctx context.Context, seriesID, startDate, endDate string) ([]Observation, error) {
+
+	params := url.Values{
+		"series_id":         {seriesID},
+		"observation_start": {startDate},
+		"observation_end":   {endDate},
+		"sort_order":        {"asc"},
+	}
+
+	var data FredResponse
+	if err := c.get(ctx, "/fred/series/observations", params, &data); err != nil {
+		return nil, err
+	}
+
+	return data.Observations, nil
+}
+
+// Series gets the metadata and observations of a FRED series between startDate
+// and endDate and summarizes the latest changes.
+func (c *Client) Series(ctx context.Context, seriesID, startDate, endDate string) (Summary, error) {
+
+	info, err := c.SeriesInfo(ctx, seriesID)
+	if err != nil {
+		return Summary{SeriesID: seriesID}, err
+	}
+
+	observations, err := c.Observations(ctx, seriesID, startDate, endDate)
+	if err != nil {
+		return Summary{SeriesID: seriesID, Title: info.Title}, err
+	}
+
+	return Summarize(info, observations), nil
+}
+
+// Summarize summarizes the latest changes of a series from its observations. The previous
+// period and year ago observations are found by date using the series' frequency, so
+// weekly and daily series are compared with the right dates. Missing values (".") are skipped.
+func Summarize(info SeriesInfo, observations []Observation) Summary {
+
+	p := parsePeriod(info.Frequency)
+
+	summary := Summary{
+		SeriesID:         info.ID,
+		Title:            info.Title,
+		Frequency:        info.Frequency,
+		Units:            info.Units,
+		PercentagePoints: inPercent(info.Units),
+		Period:           p.Name,
+	}
+
+	var parsed []observation
+	for _, o := range observations {
+		date, err := time.Parse("2006-01-02", o.Date)
+		if err != nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(o.Value, 64)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, observation{Observation: o, date: date, value: value})
+	}
+
+	summary.Observations = len(parsed)
+	if len(parsed) == 0 {
+		return summary
+	}
+
+	// Sort newest first so earlier observations are found by walking back from the latest
+	sort.Slice(parsed, func(i, j int) bool {
+		return parsed[i].date.After(parsed[j].date)
+	})
+
+	latest := parsed[0]
+	earlier := parsed[1:]
+	summary.Date = latest.Observation.Date
+	summary.Value = latest.Observation.Value
+
+	if previous, ok := p.valueOn(p.back(latest.date, 1), earlier); ok {
+		summary.Previous = newChange(latest, previous)
+		// Compounding a rate's change, e.g. the unemployment rate's, means nothing
+		if p.PerYear > 0 && !summary.PercentagePoints && latest.value > 0 && previous.value > 0 {
+			annualized := (math.Pow(latest.value/previous.value, p.PerYear) - 1) * 100
+			summary.Previous.AnnualizedPercentChange = &annualized
+		}
+	}
+
+	if yearAgo, ok := p.valueOn(p.yearsBack(latest.date, 1), earlier); ok {
+		summary.YearAgo = newChange(latest, yearAgo)
+	}
+
+	if fiveYearsAgo, ok := p.valueOn(p.yearsBack(latest.date, 5), earlier); ok {
+		summary.FiveYearsAgo = newChange(latest, fiveYearsAgo)
+	}
+
+	return summary
+}
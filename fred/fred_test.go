@@ -0,0 +1,444 @@
+package fred
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"polyapi/pool"
+	"polyapi/store"
+)
+
+func TestSeries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("series_id") != "FEDFUNDS" || query.Get("api_key") != "test-key" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		switch r.URL.Path {
+		case "/fred/series":
+			http.ServeFile(w, r, filepath.Join("testdata", "fedfunds_series.json"))
+		case "/fred/series/observations":
+			if query.Get("observation_start") != "2023-07-01" || query.Get("observation_end") != "2024-08-26" {
+				t.Errorf("unexpected dates: %s", r.URL)
+			}
+			http.ServeFile(w, r, filepath.Join("testdata", "fedfunds.json"))
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+
+	summary, err := client.Series(context.Background(), "FEDFUNDS", "2023-07-01", "2024-08-26")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Title != "Federal Funds Rate" || summary.Period != "month" || summary.Observations != 13 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if summary.Date != "2024-07-01" || summary.Value != "5.33" {
+		t.Errorf("latest = %s %s", summary.Date, summary.Value)
+	}
+	if summary.Previous == nil || summary.Previous.Date != "2024-06-01" || math.Abs(summary.Previous.Change-0.03) > 1e-9 {
+		t.Errorf("previous = %+v", summary.Previous)
+	}
+	if summary.YearAgo == nil || summary.YearAgo.Date != "2023-07-01" || math.Abs(summary.YearAgo.Change-0.21) > 1e-9 {
+		t.Errorf("year ago = %+v", summary.YearAgo)
+	}
+}
+
+func TestSummarizeByFrequency(t *testing.T) {
+	tests := []struct {
+		frequency    string
+		observations []Observation
+		previous     string
+		yearAgo      string
+		annualized   bool
+	}{
+		{
+			// Monday is compared with Friday, skipping the "." holiday and the weekend
+			frequency: "Daily",
+			observations: []Observation{
+				{"2023-09-01", "5.28"}, {"2023-09-04", "."}, {"2024-08-30", "5.15"}, {"2024-09-02", "."}, {"2024-09-03", "5.13"},
+			},
+			previous: "2024-08-30",
+			yearAgo:  "2023-09-01",
+		},
+		{
+			frequency: "Weekly, Ending Saturday",
+			observations: []Observation{
+				{"2023-08-19", "232000"}, {"2024-08-10", "228000"}, {"2024-08-17", "233000"},
+			},
+			previous:   "2024-08-10",
+			yearAgo:    "2023-08-19",
+			annualized: true,
+		},
+		{
+			// A missing quarter isn't compared with the one before it
+			frequency: "Quarterly",
+			observations: []Observation{
+				{"2023-04-01", "27453.815"}, {"2024-01-01", "28269.174"}, {"2024-04-01", "."},
+			},
+			previous: "",
+			yearAgo:  "",
+		},
+		{
+			frequency: "Quarterly",
+			observations: []Observation{
+				{"2023-04-01", "27453.815"}, {"2024-01-01", "28269.174"}, {"2024-04-01", "28629.153"},
+			},
+			previous:   "2024-01-01",
+			yearAgo:    "2023-04-01",
+			annualized: true,
+		},
+	}
+
+	for _, test := range tests {
+		summary := Summarize(SeriesInfo{ID: "TEST", Frequency: test.frequency}, test.observations)
+
+		var previous, yearAgo string
+		var annualized bool
+		if summary.Previous != nil {
+			previous = summary.Previous.Date
+			annualized = summary.Previous.AnnualizedPercentChange != nil
+		}
+		if summary.YearAgo != nil {
+			yearAgo = summary.YearAgo.Date
+		}
+		if previous != test.previous || yearAgo != test.yearAgo || annualized != test.annualized {
+			t.Errorf("%s: previous = %q, year ago = %q, annualized = %v; want %q, %q, %v",
+				test.frequency, previous, yearAgo, annualized, test.previous, test.yearAgo, test.annualized)
+		}
+	}
+}
+
+func TestSummarizePercent(t *testing.T) {
+	info := SeriesInfo{ID: "UNRATE", Title: "Unemployment Rate", Frequency: "Monthly", Units: "Percent"}
+	summary := Summarize(info, []Observation{{"2023-08-01", "3.8"}, {"2024-07-01", "4.3"}, {"2024-08-01", "4.2"}})
+
+	// A rate's change is in percentage points and isn't annualized
+	if !summary.PercentagePoints || summary.Previous == nil || summary.Previous.AnnualizedPercentChange != nil {
+		t.Fatalf("summary = %+v", summary)
+	}
+	var b strings.Builder
+	Report{Series: []Summary{summary}}.PrintTable(&b)
+	for _, want := range []string{"Change from previous month (2024-07-01): -0.10 percentage points", "Change from a year ago (2023-08-01): 0.40 percentage points"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestSeriesInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fred/series" || r.URL.Query().Get("series_id") != "UNRATE" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", "series.json"))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+
+	info, err := client.SeriesInfo(context.Background(), "UNRATE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SeriesInfo{ID: "UNRATE", Title: "Unemployment Rate", Frequency: "Monthly", Units: "Percent", SeasonalAdjustment: "Seasonally Adjusted",
+		LastUpdated: "2024-08-02 07:48:02-05", ObservationStart: "1948-01-01", ObservationEnd: "2024-07-01"}
+	if info != want {
+		t.Errorf("info = %+v, want %+v", info, want)
+	}
+}
+
+func TestSeriesRequiresAPIKey(t *testing.T) {
+	client := NewClient("")
+	if _, err := client.Series(context.Background(), "FEDFUNDS", "2023-07-01", "2024-08-26"); err == nil {
+		t.Error("expected an error without an API key")
+	}
+}
+
+func TestProviderFetchesIncrementally(t *testing.T) {
+	var starts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		starts = append(starts, r.URL.Query().Get("observation_start"))
+		if len(starts) > 1 {
+			w.Write([]byte(`{"observations": [{"date": "2024-08-01", "value": "5.33"}]}`))
+			return
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", "fedfunds.json"))
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	client := NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := NewProvider(client, s)
+	info := SeriesInfo{ID: "FEDFUNDS", Title: "Federal Funds Rate", Frequency: "Monthly", Units: "Percent"}
+
+	if _, err := p.Series(context.Background(), info); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := p.Series(context.Background(), info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second fetch only asks for observations after the latest stored one
+	if len(starts) != 2 || starts[1] != "2024-07-02" {
+		t.Errorf("observation_start = %v", starts)
+	}
+	if summary.Title != "Federal Funds Rate" || summary.Observations != 14 || summary.Date != "2024-08-01" || summary.Previous.Date != "2024-07-01" {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	// Stored observations are summarized when the API is down
+	server.Close()
+	summary, err = p.Series(context.Background(), info)
+	if err != nil || summary.Observations != 14 {
+		t.Errorf("offline summary = %+v, %v", summary, err)
+	}
+}
+
+func TestSearchAddsToWatchlist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/fred/series/search" || query.Get("search_text") != "consumer sentiment" || query.Get("limit") != "2" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", "series_search.json"))
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	client := NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := NewProvider(client, s)
+
+	result, err := p.Fetch(context.Background(), []string{"search", "consumer", "--add", "1", "sentiment", "--limit", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := result.(SeriesList)
+	if len(list.Series) != 2 || list.Added != "UMCSENT" {
+		t.Errorf("list = %+v", list)
+	}
+
+	watchlist, err := s.Watchlist("fred")
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := watchlist[len(watchlist)-1]
+	if added.SeriesID != "UMCSENT" || added.Label != "University of Michigan: Consumer Sentiment" || added.Units != "Index 1966:Q1=100" {
+		t.Errorf("added = %+v", added)
+	}
+}
+
+func TestRevisions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/fred/series/observations":
+			if query.Get("realtime_start") != "1776-07-04" || query.Get("observation_start") != "2024-01-01" {
+				t.Errorf("unexpected request: %s", r.URL)
+			}
+			http.ServeFile(w, r, filepath.Join("testdata", "gdp_vintages.json"))
+		case "/fred/series/vintagedates":
+			http.ServeFile(w, r, filepath.Join("testdata", "gdp_vintagedates.json"))
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	client := NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := NewProvider(client, s)
+
+	revisions, err := p.Revisions(context.Background(), "GDP", "2024-01-01", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revisions.Title != "Gross Domestic Product" || revisions.VintageDates != 4 || len(revisions.Observations) != 1 {
+		t.Fatalf("revisions = %+v", revisions)
+	}
+	revision := revisions.Observations[0]
+	if revision.FirstReleased != "2024-04-25" || revision.FirstValue != 28284.498 || revision.Value != 28624.069 ||
+		revision.Releases != 4 || len(revision.Vintages) != 4 || math.Abs(revision.Change-339.571) > 1e-6 {
+		t.Errorf("revision = %+v", revision)
+	}
+
+	// The value as published before the annual revision comes from the stored vintages
+	server.Close()
+	revisions, err = p.Revisions(context.Background(), "GDP", "2024-01-01", "2024-07-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision := revisions.Observations[0]; revision.Value != 28269.174 || revision.Released != "2024-06-27" || revision.Releases != 3 {
+		t.Errorf("as of revision = %+v", revision)
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/fred/releases/dates":
+			if query.Get("realtime_start") != "2024-07-26" || query.Get("realtime_end") != "2024-09-01" ||
+				query.Get("include_release_dates_with_no_data") != "true" {
+				t.Errorf("unexpected request: %s", r.URL)
+			}
+			http.ServeFile(w, r, filepath.Join("testdata", "release_dates.json"))
+		case "/fred/series/release":
+			if query.Get("series_id") != "RSXFS" {
+				t.Errorf("unexpected request: %s", r.URL)
+			}
+			http.ServeFile(w, r, filepath.Join("testdata", "series_release.json"))
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// A series added without a release, one fetched since its release and one with a stored value
+	if err := s.AddSeries(store.Series{Source: "fred", SeriesID: "RSXFS", Label: "Retail Sales: Retail Trade"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MarkSeriesFetched("fred", "UNRATE"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveObservations([]store.Observation{{Source: "fred", SeriesID: "GDP", Date: "2024-04-01", Value: 28629.153}}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := NewProvider(client, s)
+
+	today := time.Date(2024, 8, 26, 0, 0, 0, 0, time.Local)
+	calendar, err := p.calendar(context.Background(), today, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calendar.Start != "2024-08-26" || calendar.End != "2024-09-01" || len(calendar.Releases) != 2 {
+		t.Fatalf("calendar = %+v", calendar)
+	}
+	gdp := calendar.Releases[0]
+	if gdp.SeriesID != "GDP" || gdp.Date != "2024-08-29" || gdp.LastDate != "2024-04-01" || gdp.LastValue == nil || *gdp.LastValue != 28629.153 {
+		t.Errorf("GDP release = %+v", gdp)
+	}
+	if claims := calendar.Releases[1]; claims.SeriesID != "ICSA" || claims.LastValue != nil {
+		t.Errorf("claims release = %+v", claims)
+	}
+
+	// Every series released before today is stale except UNRATE, which was fetched after its release
+	var stale []string
+	for _, entry := range calendar.Stale {
+		stale = append(stale, entry.SeriesID)
+	}
+	want := []string{"CUUR0000SA0L1E", "CUSR0000SA0", "LNS14000000", "CES0000000001", "RSAFS", "RSXFS"}
+	if fmt.Sprint(stale) != fmt.Sprint(want) {
+		t.Errorf("stale = %v, want %v", stale, want)
+	}
+
+	// The release looked up for RSXFS is saved
+	watchlist, err := s.Watchlist("fred")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added := watchlist[len(watchlist)-1]; added.SeriesID != "RSXFS" || added.ReleaseID != 9 {
+		t.Errorf("added series = %+v", added)
+	}
+}
+
+func TestDashboardFetchesConcurrently(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		http.ServeFile(w, r, filepath.Join("testdata", "fedfunds.json"))
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	client := NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := NewProvider(client, s)
+
+	report, err := p.Dashboard(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchlist, err := s.Watchlist("fred")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The series are listed in watchlist order however the requests finish
+	if len(report.Series) != len(watchlist) {
+		t.Fatalf("got %d series, want %d", len(report.Series), len(watchlist))
+	}
+	for i, series := range watchlist {
+		if report.Series[i].SeriesID != series.SeriesID || report.Series[i].Error != "" {
+			t.Errorf("series %d = %+v, want %s", i, report.Series[i], series.SeriesID)
+		}
+	}
+	// The whole watchlist is fetched at once, in about one round trip
+	if n := maxInFlight.Load(); int(n) != pool.Size(len(watchlist)) {
+		t.Errorf("%d requests at once, want %d", n, pool.Size(len(watchlist)))
+	}
+}
@@ -0,0 +1,94 @@
+package fred
+
+import (
+	"strings"
+	"time"
+)
+
+// period is how often a FRED series is published, used to find the observations
+// one period and one year before the latest by date rather than by position.
+type period struct {
+	// Name is the period in change descriptions, e.g. "month".
+	Name string
+	// PerYear is the number of periods in a year, used to annualize the change
+	// from the previous period. It is 0 when annualizing doesn't make sense.
+	PerYear float64
+	// back returns the date n periods before t.
+	back func(t time.Time, n int) time.Time
+	// window is how far before a target date an observation may be and still be
+	// compared, e.g. Friday's rate for a daily series compared on Monday.
+	window func(t time.Time) time.Time
+}
+
+func days(d int) func(t time.Time, n int) time.Time {
+	return func(t time.Time, n int) time.Time { return t.AddDate(0, 0, -d*n) }
+}
+
+func months(m int) func(t time.Time, n int) time.Time {
+	return func(t time.Time, n int) time.Time { return t.AddDate(0, -m*n, 0) }
+}
+
+// parsePeriod returns the period of a FRED frequency such as "Monthly" or
+// "Weekly, Ending Saturday". Unknown frequencies are treated as monthly.
+func parsePeriod(frequency string) period {
+
+	var p period
+	switch name, _, _ := strings.Cut(strings.ToLower(frequency), ","); strings.TrimSpace(name) {
+	case "daily":
+		p = period{Name: "day", back: days(1)}
+	case "weekly":
+		p = period{Name: "week", PerYear: 52, back: days(7)}
+	case "biweekly":
+		p = period{Name: "two weeks", PerYear: 26, back: days(14)}
+	case "quarterly":
+		p = period{Name: "quarter", PerYear: 4, back: months(3)}
+	case "semiannual":
+		p = period{Name: "half year", PerYear: 2, back: months(6)}
+	case "annual":
+		p = period{Name: "year", back: months(12)}
+	default:
+		p = period{Name: "month", PerYear: 12, back: months(1)}
+	}
+
+	p.window = func(t time.Time) time.Time { return p.back(t, 1) }
+	if p.Name == "day" {
+		// Daily series skip weekends and holidays
+		p.window = func(t time.Time) time.Time { return t.AddDate(0, 0, -7) }
+	}
+
+	return p
+}
+
+// yearsBack returns the date the given number of years before t, counted in periods
+// so that e.g. a weekly series is compared with the same weekday 52 weeks earlier.
+func (p period) yearsBack(t time.Time, years int) time.Time {
+	if p.PerYear == 0 {
+		return t.AddDate(-years, 0, 0)
+	}
+	return p.back(t, years*int(p.PerYear))
+}
+
+// valueOn returns the latest observation on or before target but after the period's
+// window before it, or false if there is none. observations are sorted newest first.
+func (p period) valueOn(target time.Time, observations []observation) (observation, bool) {
+
+	earliest := p.window(target)
+	for _, o := range observations {
+		if o.date.After(target) {
+			continue
+		}
+		if !o.date.After(earliest) {
+			break
+		}
+		return o, true
+	}
+
+	return observation{}, false
+}
+
+// observation is a FRED observation with its date and value parsed.
+type observation struct {
+	Observation
+	date  time.Time
+	value float64
+}
@@ -0,0 +1,331 @@
+package fred
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"polyapi/output"
+	"polyapi/pool"
+	"polyapi/provider"
+	"polyapi/store"
+)
+
+// source identifies FRED series in the series_observations table.
+const source = "fred"
+
+// HistoryYears is how many years of a series are fetched the first time it's shown.
+const HistoryYears = 10
+
+// Provider gets the FRED dashboard of the series on the watchlist, keeping each series'
+// observations in the database so only new ones are fetched.
+type Provider struct {
+	Client *Client
+	Store  *store.Store
+}
+
+// NewProvider returns a FRED provider that stores observations in s.
+func NewProvider(client *Client, s *store.Store) *Provider {
+	return &Provider{Client: client, Store: s}
+}
+
+func (p *Provider) Name() string { return "fred" }
+
+func (p *Provider) Description() string {
+	return "Federal Reserve (FRED) data like the federal funds rate and GDP (fred [search TEXT | series ID | category [ID] | revisions ID] [--add N])"
+}
+
+func (p *Provider) RequiredConfig() []string { return []string{"FRED_API_KEY"} }
+
+// Fetch returns the dashboard of watchlist series without arguments. The search, series
+// and category subcommands browse the FRED catalog, and --add N adds the Nth series listed
+// to the watchlist. The revisions subcommand shows how observations were revised.
+func (p *Provider) Fetch(ctx context.Context, args []string) (output.Result, error) {
+
+	if len(args) == 0 {
+		return p.Dashboard(ctx)
+	}
+
+	flags := flag.NewFlagSet("fred "+args[0], flag.ContinueOnError)
+	limit := flags.Int("limit", 25, "maximum number of series to list")
+	add := flags.Int("add", 0, "add this series number to the watchlist")
+	date := flags.String("date", "", "show every vintage of the observation on this date (YYYY-MM-DD)")
+	asOf := flags.String("as-of", "", "show the values as published on this date (YYYY-MM-DD)")
+	words, err := provider.ParseInterspersed(ctx, flags, args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch args[0] {
+	case "search":
+		if len(words) == 0 {
+			return nil, provider.Usagef("usage: fred search TEXT [--limit N] [--add N]")
+		}
+		text := strings.Join(words, " ")
+		series, err := p.Client.Search(ctx, text, *limit)
+		if err != nil {
+			return nil, err
+		}
+		list := SeriesList{Title: fmt.Sprintf("FRED series matching %q", text), Series: series}
+		if err := p.addFromList(&list, *add); err != nil {
+			return nil, err
+		}
+		return list, nil
+	case "series":
+		if len(words) != 1 {
+			return nil, provider.Usagef("usage: fred series SERIES_ID [--add 1]")
+		}
+		info, err := p.Client.SeriesInfo(ctx, strings.ToUpper(words[0]))
+		if err != nil {
+			return nil, err
+		}
+		list := SeriesList{Title: "FRED series", Series: []SeriesInfo{info}}
+		if err := p.addFromList(&list, *add); err != nil {
+			return nil, err
+		}
+		return list, nil
+	case "category":
+		categoryID := 0
+		if len(words) > 0 {
+			categoryID, err = strconv.Atoi(words[0])
+			if err != nil {
+				return nil, provider.Usagef("invalid category ID: %s", words[0])
+			}
+		}
+		listing, err := p.Client.Category(ctx, categoryID, *limit)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.addFromList(&listing.SeriesList, *add); err != nil {
+			return nil, err
+		}
+		return listing, nil
+	case "revisions":
+		if len(words) != 1 {
+			return nil, provider.Usagef("usage: fred revisions SERIES_ID [--date YYYY-MM-DD] [--as-of YYYY-MM-DD]")
+		}
+		return p.Revisions(ctx, strings.ToUpper(words[0]), *date, *asOf)
+	default:
+		return nil, provider.Usagef("unknown fred subcommand: %s (use search, series, category or revisions)", args[0])
+	}
+}
+
+// addFromList adds the nth series of list to the watchlist; n of 0 adds nothing.
+func (p *Provider) addFromList(list *SeriesList, n int) error {
+
+	if n == 0 {
+		return nil
+	}
+	if n < 1 || n > len(list.Series) {
+		return provider.Usagef("series number out of range: %d", n)
+	}
+
+	if err := p.Watch(list.Series[n-1]); err != nil {
+		return err
+	}
+	list.Added = list.Series[n-1].ID
+
+	return nil
+}
+
+// Watch adds a series to the watchlist so it's shown on the dashboard.
+func (p *Provider) Watch(info SeriesInfo) error {
+	return p.Store.AddSeries(store.Series{Source: source, SeriesID: info.ID, Label: info.Title, Frequency: info.Frequency, Units: info.Units})
+}
+
+// Dashboard updates and summarizes each FRED series on the watchlist, a few at a time.
+func (p *Provider) Dashboard(ctx context.Context) (Report, error) {
+
+	var report Report
+
+	if p.Client.APIKey == "" {
+		return report, fmt.Errorf("FRED_API_KEY environment variable is not set")
+	}
+
+	watchlist, err := p.Store.Watchlist(source)
+	if err != nil {
+		return report, err
+	}
+
+	// The series are fetched concurrently and listed in watchlist order
+	report.Series = make([]Summary, len(watchlist))
+	err = pool.Run(ctx, pool.Size(len(watchlist)), len(watchlist), func(ctx context.Context, i int) {
+		series := watchlist[i]
+		info := SeriesInfo{ID: series.SeriesID, Title: series.Label, Frequency: series.Frequency, Units: series.Units}
+		summary, err := p.Series(ctx, info)
+		if err != nil {
+			summary.Error = err.Error()
+		}
+		report.Series[i] = summary
+	})
+
+	return report, err
+}
+
+// Series fetches the observations of a series after the latest stored one, saves them and
+// summarizes the stored series. If the API can't be reached the stored observations are used.
+func (p *Provider) Series(ctx context.Context, info SeriesInfo) (Summary, error) {
+
+	summary := Summary{SeriesID: info.ID, Title: info.Title}
+
+	// Changes are calculated by frequency, so look it up if it wasn't given when the series was added
+	if info.Frequency == "" {
+		metadata, err := p.Client.SeriesInfo(ctx, info.ID)
+		if err != nil {
+			log.Printf("Error getting the frequency of FRED series %s, treating it as monthly: %v", info.ID, err)
+		}
+		info.Frequency = metadata.Frequency
+	}
+
+	updateErr := p.update(ctx, info.ID)
+
+	stored, err := p.Store.Observations(source, info.ID)
+	if err != nil {
+		return summary, err
+	}
+	if updateErr != nil {
+		if len(stored) == 0 {
+			return summary, updateErr
+		}
+		log.Printf("Error updating FRED series %s, using %d stored observations: %v", info.ID, len(stored), updateErr)
+	}
+
+	var observations []Observation
+	for _, observation := range stored {
+		observations = append(observations, Observation{Date: observation.Date, Value: strconv.FormatFloat(observation.Value, 'f', -1, 64)})
+	}
+
+	return Summarize(info, observations), nil
+}
+
+// RevisionYears is how many years of observations are shown by Revisions.
+const RevisionYears = 2
+
+// Revisions fetches and saves every vintage of a series' observations, then shows how each
+// was revised since its first release. With date only that observation is shown, with
+// all its vintages, and with asOf the values are those published on that date.
+// If ALFRED can't be reached the stored vintages are used.
+func (p *Provider) Revisions(ctx context.Context, seriesID, date, asOf string) (Revisions, error) {
+
+	for _, d := range []string{date, asOf} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return Revisions{}, provider.Usagef("invalid date %q, use YYYY-MM-DD", d)
+		}
+	}
+
+	start := time.Now().AddDate(-RevisionYears, 0, 0).Format("2006-01-02")
+	end := time.Now().Format("2006-01-02")
+	if date != "" {
+		start, end = date, date
+	}
+
+	title := seriesID
+	watchlist, err := p.Store.Watchlist(source)
+	if err != nil {
+		return Revisions{}, err
+	}
+	for _, series := range watchlist {
+		if series.SeriesID == seriesID {
+			title = series.Label
+		}
+	}
+
+	updateErr := p.updateVintages(ctx, seriesID, start, end)
+
+	stored, err := p.Store.Vintages(source, seriesID, start, end)
+	if err != nil {
+		return Revisions{}, err
+	}
+	if updateErr != nil {
+		if len(stored) == 0 {
+			return Revisions{}, updateErr
+		}
+		log.Printf("Error updating vintages of FRED series %s, using %d stored vintages: %v", seriesID, len(stored), updateErr)
+	}
+
+	revisions := newRevisions(seriesID, title, stored, asOf, date != "")
+
+	if updateErr == nil {
+		vintageDates, err := p.Client.VintageDates(ctx, seriesID)
+		if err != nil {
+			log.Printf("Error getting vintage dates of FRED series %s: %v", seriesID, err)
+		}
+		revisions.VintageDates = len(vintageDates)
+	}
+
+	return revisions, nil
+}
+
+// updateVintages fetches and saves every vintage of the observations between start and end.
+func (p *Provider) updateVintages(ctx context.Context, seriesID, start, end string) error {
+
+	vintages, err := p.Client.Vintages(ctx, seriesID, start, end)
+	if err != nil {
+		return err
+	}
+
+	var fetched []store.Vintage
+	for _, vintage := range vintages {
+		// Vintages from before an observation was first released have the missing value "."
+		value, err := strconv.ParseFloat(vintage.Value, 64)
+		if err != nil {
+			continue
+		}
+		fetched = append(fetched, store.Vintage{Source: source, SeriesID: seriesID, Date: vintage.Date,
+			RealtimeStart: vintage.RealtimeStart, RealtimeEnd: vintage.RealtimeEnd, Value: value})
+	}
+
+	return p.Store.SaveVintages(fetched)
+}
+
+// update fetches and saves the observations of a series after the latest stored one,
+// or the last HistoryYears of it if none are stored.
+func (p *Provider) update(ctx context.Context, seriesID string) error {
+
+	today := time.Now()
+	start := today.AddDate(-HistoryYears, 0, 0)
+
+	latest, err := p.Store.LatestObservationDate(source, seriesID)
+	if err != nil {
+		return err
+	}
+	if latest != "" {
+		latestDate, err := time.Parse("2006-01-02", latest)
+		if err != nil {
+			return err
+		}
+		start = latestDate.AddDate(0, 0, 1)
+	}
+
+	// Nothing can be newer than today
+	if start.After(today) {
+		return nil
+	}
+
+	observations, err := p.Client.Observations(ctx, seriesID, start.Format("2006-01-02"), today.Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+
+	var fetched []store.Observation
+	for _, observation := range observations {
+		// FRED reports missing values as "."
+		value, err := strconv.ParseFloat(observation.Value, 64)
+		if err != nil {
+			continue
+		}
+		fetched = append(fetched, store.Observation{Source: source, SeriesID: seriesID, Date: observation.Date, Value: value})
+	}
+
+	if err := p.Store.SaveObservations(fetched); err != nil {
+		return err
+	}
+	return p.Store.MarkSeriesFetched(source, seriesID)
+}
@@ -0,0 +1,77 @@
+package fred
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Release is a FRED release, a set of series published together such as the
+// Consumer Price Index or the Employment Situation.
+type Release struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Link string `json:"link,omitempty"`
+}
+
+// ReleaseDate is a date a FRED release is or was published.
+type ReleaseDate struct {
+	ReleaseID   int    `json:"release_id"`
+	ReleaseName string `json:"release_name"`
+	Date        string `json:"date"`
+}
+
+// ReleaseDatesResponse is the response of the FRED releases/dates endpoint.
+type ReleaseDatesResponse struct {
+	ReleaseDates []ReleaseDate `json:"release_dates"`
+}
+
+// MaxReleaseDates is the most release dates FRED returns in one response.
+const MaxReleaseDates = 1000
+
+// ReleaseDates gets the dates of every release published, or scheduled to be
+// published, between startDate and endDate, oldest first.
+func (c *Client) ReleaseDates(ctx context.Context, startDate, endDate string) ([]ReleaseDate, error) {
+
+	var dates []ReleaseDate
+	for {
+		params := url.Values{
+			"realtime_start": {startDate},
+			"realtime_end":   {endDate},
+			"order_by":       {"release_date"},
+			"sort_order":     {"asc"},
+			"limit":          {strconv.Itoa(MaxReleaseDates)},
+			"offset":         {strconv.Itoa(len(dates))},
+			// Scheduled releases have no data yet
+			"include_release_dates_with_no_data": {"true"},
+		}
+
+		var data ReleaseDatesResponse
+		if err := c.get(ctx, "/fred/releases/dates", params, &data); err != nil {
+			return nil, err
+		}
+		dates = append(dates, data.ReleaseDates...)
+
+		// Hundreds of releases are published each month, so the dates can take more than one page
+		if len(data.ReleaseDates) < MaxReleaseDates {
+			return dates, nil
+		}
+	}
+}
+
+// SeriesRelease gets the release that publishes a FRED series.
+func (c *Client) SeriesRelease(ctx context.Context, seriesID string) (Release, error) {
+
+	var data struct {
+		Releases []Release `json:"releases"`
+	}
+	if err := c.get(ctx, "/fred/series/release", url.Values{"series_id": {seriesID}}, &data); err != nil {
+		return Release{}, err
+	}
+	if len(data.Releases) == 0 {
+		return Release{}, fmt.Errorf("no release found for FRED series %s", seriesID)
+	}
+
+	return data.Releases[0], nil
+}
@@ -0,0 +1,140 @@
+package fred
+
+import (
+	"fmt"
+	"io"
+
+	"polyapi/output"
+)
+
+// Change is the change from an earlier observation to the latest one.
+type Change struct {
+	Date          string  `json:"date"`
+	Value         string  `json:"value"`
+	Change        float64 `json:"change"`
+	PercentChange float64 `json:"percent_change"`
+	// AnnualizedPercentChange is the change from the previous period compounded over
+	// a year, e.g. the annualized quarterly growth of GDP.
+	AnnualizedPercentChange *float64 `json:"annualized_percent_change,omitempty"`
+}
+
+// Summary is the latest observation of a FRED series with its period and year changes.
+type Summary struct {
+	SeriesID  string `json:"series_id"`
+	Title     string `json:"title"`
+	Frequency string `json:"frequency,omitempty"`
+	Units     string `json:"units,omitempty"`
+	// PercentagePoints is true for series in percent, such as UNRATE, whose changes
+	// are differences in percentage points and aren't annualized.
+	PercentagePoints bool    `json:"percentage_points,omitempty"`
+	Observations     int     `json:"observations"`
+	Period           string  `json:"period"`
+	Date             string  `json:"date,omitempty"`
+	Value            string  `json:"value,omitempty"`
+	Previous         *Change `json:"previous,omitempty"`
+	YearAgo          *Change `json:"year_ago,omitempty"`
+	FiveYearsAgo     *Change `json:"five_years_ago,omitempty"`
+	Error            string  `json:"error,omitempty"`
+}
+
+// Report is the summary of every FRED series fetched.
+type Report struct {
+	Series []Summary `json:"series"`
+}
+
+// PrintTable prints each series with a friendly header and its changes.
+func (r Report) PrintTable(w io.Writer) {
+
+	if len(r.Series) == 0 {
+		fmt.Fprintln(w, "\nNo FRED series on the watchlist. Add one with: polyapi series add fred SERIES_ID")
+		fmt.Fprintln(w)
+	}
+
+	for _, series := range r.Series {
+
+		if series.Error != "" {
+			fmt.Fprintf(w, "\n%s: %s\n", series.SeriesID, series.Error)
+			continue
+		}
+
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "**%s**", series.Title)
+		fmt.Fprintf(w, "\nFRED Series ID: %s and %d observations", series.SeriesID, series.Observations)
+		fmt.Fprintf(w, "\nCharts and more info: https://fred.stlouisfed.org/series/%s", series.SeriesID)
+		fmt.Fprintln(w)
+
+		if series.Observations == 0 {
+			fmt.Fprintln(w, "No data available in the specified date range")
+			fmt.Fprintln(w)
+			continue
+		}
+
+		if series.Units != "" {
+			fmt.Fprintf(w, "%s %s on %s\n", series.Value, series.Units, series.Date)
+		} else {
+			fmt.Fprintf(w, "%s on %s\n", series.Value, series.Date)
+		}
+
+		if series.Previous != nil {
+			fmt.Fprintf(w, "Change from previous %s (%s): %s | Value: %s", series.Period, series.Previous.Date, series.formatChange(series.Previous), series.Previous.Value)
+			if series.Previous.AnnualizedPercentChange != nil {
+				fmt.Fprintf(w, " | Annualized: %.2f%%", *series.Previous.AnnualizedPercentChange)
+			}
+			fmt.Fprintln(w)
+		} else {
+			fmt.Fprintf(w, "Not enough data to calculate the change from the previous %s\n", series.Period)
+		}
+
+		if series.YearAgo != nil {
+			fmt.Fprintf(w, "Change from a year ago (%s): %s | Value: %s\n", series.YearAgo.Date, series.formatChange(series.YearAgo), series.YearAgo.Value)
+		} else {
+			fmt.Fprintln(w, "Not enough data to calculate the change from a year ago")
+		}
+		if series.FiveYearsAgo != nil {
+			fmt.Fprintf(w, "Change from 5 years ago (%s): %s | Value: %s\n", series.FiveYearsAgo.Date, series.formatChange(series.FiveYearsAgo), series.FiveYearsAgo.Value)
+		}
+
+		fmt.Fprintln(w)
+	}
+}
+
+// formatChange formats a change with its percent change, e.g. "0.25 (1.10%)", or in
+// percentage points for a series in percent, e.g. "-0.10 percentage points".
+func (s Summary) formatChange(change *Change) string {
+	if s.PercentagePoints {
+		return fmt.Sprintf("%.2f percentage points", change.Change)
+	}
+	return fmt.Sprintf("%.2f (%.2f%%)", change.Change, change.PercentChange)
+}
+
+// CSVHeader returns the series columns.
+func (r Report) CSVHeader() []string {
+	return []string{"series_id", "title", "frequency", "units", "date", "value", "period", "previous_date", "previous_value", "previous_change",
+		"previous_percent_change", "previous_annualized_percent_change", "year_ago_date", "year_ago_value", "year_ago_change", "year_ago_percent_change",
+		"five_years_ago_date", "five_years_ago_value", "five_years_ago_change", "five_years_ago_percent_change", "error"}
+}
+
+// CSVRows returns one row per series.
+func (r Report) CSVRows() [][]string {
+	var rows [][]string
+	for _, series := range r.Series {
+		row := []string{series.SeriesID, series.Title, series.Frequency, series.Units, series.Date, series.Value, series.Period}
+		for i, change := range []*Change{series.Previous, series.YearAgo, series.FiveYearsAgo} {
+			if change != nil {
+				row = append(row, change.Date, change.Value, output.FormatFloat(change.Change), output.FormatFloat(change.PercentChange))
+			} else {
+				row = append(row, "", "", "", "")
+			}
+			// Only the change from the previous period is annualized
+			if i == 0 {
+				var annualized *float64
+				if change != nil {
+					annualized = change.AnnualizedPercentChange
+				}
+				row = append(row, output.FormatOptional(annualized))
+			}
+		}
+		rows = append(rows, append(row, series.Error))
+	}
+	return rows
+}
@@ -0,0 +1,181 @@
+package fred
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+
+	"polyapi/output"
+	"polyapi/store"
+)
+
+// Vintage is the value of an observation as published from RealtimeStart until it was
+// revised on RealtimeEnd, from ALFRED (ArchivaL FRED).
+type Vintage struct {
+	Date          string `json:"date"`
+	RealtimeStart string `json:"realtime_start"`
+	RealtimeEnd   string `json:"realtime_end"`
+	Value         string `json:"value"`
+}
+
+// VintageResponse is the observations response when it's requested for every vintage.
+type VintageResponse struct {
+	Observations []Vintage `json:"observations"`
+}
+
+// Vintages gets every published value of the observations of a FRED series between
+// startDate and endDate, from each first release until today.
+func (c *Client) Vintages(ctx context.Context, seriesID, startDate, endDate string) ([]Vintage, error) {
+
+	params := url.Values{
+		"series_id":         {seriesID},
+		"observation_start": {startDate},
+		"observation_end":   {endDate},
+		"realtime_start":    {"1776-07-04"},
+		"realtime_end":      {"9999-12-31"},
+	}
+
+	var data VintageResponse
+	if err := c.get(ctx, "/fred/series/observations", params, &data); err != nil {
+		return nil, err
+	}
+
+	return data.Observations, nil
+}
+
+// VintageDates gets the dates a FRED series was released or revised, oldest first.
+func (c *Client) VintageDates(ctx context.Context, seriesID string) ([]string, error) {
+
+	var data struct {
+		VintageDates []string `json:"vintage_dates"`
+	}
+	if err := c.get(ctx, "/fred/series/vintagedates", url.Values{"series_id": {seriesID}}, &data); err != nil {
+		return nil, err
+	}
+
+	return data.VintageDates, nil
+}
+
+// VintageValue is the value of an observation from one release until the next.
+type VintageValue struct {
+	RealtimeStart string  `json:"realtime_start"`
+	RealtimeEnd   string  `json:"realtime_end"`
+	Value         float64 `json:"value"`
+}
+
+// Revision is how an observation changed from its first release to the latest
+// vintage, or to the vintage published as of a date.
+type Revision struct {
+	Date          string         `json:"date"`
+	FirstReleased string         `json:"first_released"`
+	FirstValue    float64        `json:"first_value"`
+	Released      string         `json:"released"`
+	Value         float64        `json:"value"`
+	Change        float64        `json:"change"`
+	PercentChange float64        `json:"percent_change"`
+	Releases      int            `json:"releases"`
+	Vintages      []VintageValue `json:"vintages,omitempty"`
+}
+
+// Revisions are the revisions of a series' observations.
+type Revisions struct {
+	SeriesID     string     `json:"series_id"`
+	Title        string     `json:"title"`
+	AsOf         string     `json:"as_of,omitempty"`
+	VintageDates int        `json:"vintage_dates,omitempty"`
+	Observations []Revision `json:"observations"`
+}
+
+// newRevisions builds the revision of each observation from its stored vintages, which
+// are ordered by observation date and then release date. With asOf, only vintages
+// released by then are used. With details, each revision includes all its vintages.
+func newRevisions(seriesID, title string, vintages []store.Vintage, asOf string, details bool) Revisions {
+
+	revisions := Revisions{SeriesID: seriesID, Title: title, AsOf: asOf}
+
+	for _, vintage := range vintages {
+		if asOf != "" && vintage.RealtimeStart > asOf {
+			continue
+		}
+
+		n := len(revisions.Observations)
+		if n == 0 || revisions.Observations[n-1].Date != vintage.Date {
+			revisions.Observations = append(revisions.Observations, Revision{
+				Date:          vintage.Date,
+				FirstReleased: vintage.RealtimeStart,
+				FirstValue:    vintage.Value,
+			})
+			n++
+		}
+
+		revision := &revisions.Observations[n-1]
+		revision.Released = vintage.RealtimeStart
+		revision.Value = vintage.Value
+		revision.Change = vintage.Value - revision.FirstValue
+		if revision.FirstValue != 0 {
+			revision.PercentChange = revision.Change / revision.FirstValue * 100
+		}
+		revision.Releases++
+		if details {
+			revision.Vintages = append(revision.Vintages, VintageValue{RealtimeStart: vintage.RealtimeStart, RealtimeEnd: vintage.RealtimeEnd, Value: vintage.Value})
+		}
+	}
+
+	return revisions
+}
+
+// PrintTable prints each observation's first release and latest value, followed by
+// every vintage when the revisions of a single observation were requested.
+func (r Revisions) PrintTable(w io.Writer) {
+
+	fmt.Fprintf(w, "\n**%s** revisions (FRED Series ID: %s)\n", r.Title, r.SeriesID)
+	if r.AsOf != "" {
+		fmt.Fprintf(w, "As published on %s\n", r.AsOf)
+	}
+	if r.VintageDates > 0 {
+		fmt.Fprintf(w, "%d vintages in ALFRED: https://alfred.stlouisfed.org/series?seid=%s\n", r.VintageDates, r.SeriesID)
+	}
+	fmt.Fprintln(w)
+
+	if len(r.Observations) == 0 {
+		fmt.Fprintln(w, "No vintages found")
+		fmt.Fprintln(w)
+		return
+	}
+
+	fmt.Fprintf(w, "%-12s %-26s %-26s %-22s %s\n", "Date", "First release", "Latest release", "Revision", "Releases")
+	for _, revision := range r.Observations {
+		fmt.Fprintf(w, "%-12s %-26s %-26s %-22s %d\n", revision.Date,
+			fmt.Sprintf("%s on %s", output.FormatFloat(revision.FirstValue), revision.FirstReleased),
+			fmt.Sprintf("%s on %s", output.FormatFloat(revision.Value), revision.Released),
+			fmt.Sprintf("%+.2f (%+.2f%%)", revision.Change, revision.PercentChange),
+			revision.Releases)
+
+		for _, vintage := range revision.Vintages {
+			until := "now"
+			if vintage.RealtimeEnd != "9999-12-31" {
+				until = vintage.RealtimeEnd
+			}
+			fmt.Fprintf(w, "  %s to %-12s %s\n", vintage.RealtimeStart, until, output.FormatFloat(vintage.Value))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the revision columns.
+func (r Revisions) CSVHeader() []string {
+	return []string{"series_id", "date", "first_released", "first_value", "released", "value", "change", "percent_change", "releases"}
+}
+
+// CSVRows returns one row per observation.
+func (r Revisions) CSVRows() [][]string {
+	var rows [][]string
+	for _, revision := range r.Observations {
+		rows = append(rows, []string{r.SeriesID, revision.Date, revision.FirstReleased, output.FormatFloat(revision.FirstValue),
+			revision.Released, output.FormatFloat(revision.Value), output.FormatFloat(revision.Change),
+			output.FormatFloat(revision.PercentChange), strconv.Itoa(revision.Releases)})
+	}
+	return rows
+}
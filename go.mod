@@ -2,4 +2,9 @@ module polyapi
 
 go 1.22
 
-require github.com/mattn/go-sqlite3 v1.14.13
+require (
+	github.com/mattn/go-sqlite3 v1.14.13
+	golang.org/x/term v0.25.0
+)
+
+require golang.org/x/sys v0.26.0 // indirect
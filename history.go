@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"polyapi/output"
+	"polyapi/store"
+)
+
+// sparklineWidth is the most bars drawn in a history sparkline.
+const sparklineWidth = 60
+
+// HistoryPoint is a single recorded value.
+type HistoryPoint struct {
+	Time   time.Time `json:"time"`
+	Value  float64   `json:"value"`
+	Source string    `json:"source,omitempty"`
+}
+
+// History is the recorded temperatures of an address or prices of a ticker symbol
+// over a date range, with their min, max and average.
+type History struct {
+	Title   string         `json:"title"`
+	Unit    string         `json:"unit"`
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Min     *HistoryPoint  `json:"min,omitempty"`
+	Max     *HistoryPoint  `json:"max,omitempty"`
+	Average *float64       `json:"average,omitempty"`
+	Points  []HistoryPoint `json:"points"`
+}
+
+// newHistory summarizes the points recorded between from and to.
+func newHistory(title, unit string, from, to time.Time, points []HistoryPoint) History {
+
+	history := History{
+		Title:  title,
+		Unit:   unit,
+		From:   from.Format("2006-01-02"),
+		To:     to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Points: points,
+	}
+
+	if len(points) == 0 {
+		return history
+	}
+
+	var sum float64
+	history.Min, history.Max = &points[0], &points[0]
+	for i, point := range points {
+		sum += point.Value
+		if point.Value < history.Min.Value {
+			history.Min = &points[i]
+		}
+		if point.Value > history.Max.Value {
+			history.Max = &points[i]
+		}
+	}
+	average := sum / float64(len(points))
+	history.Average = &average
+
+	return history
+}
+
+// PrintTable prints the summary, a sparkline and the latest values.
+func (h History) PrintTable(w io.Writer) {
+
+	fmt.Fprintf(w, "\n%s from %s to %s\n\n", h.Title, h.From, h.To)
+
+	if len(h.Points) == 0 {
+		fmt.Fprintln(w, "No history recorded in this date range.")
+		return
+	}
+
+	values := make([]float64, len(h.Points))
+	for i, point := range h.Points {
+		values[i] = point.Value
+	}
+
+	fmt.Fprintf(w, "  Readings: %d\n", len(h.Points))
+	fmt.Fprintf(w, "  Min:      %.2f%s on %s\n", h.Min.Value, h.Unit, h.Min.Time.Local().Format("2006-01-02 03:04 PM"))
+	fmt.Fprintf(w, "  Max:      %.2f%s on %s\n", h.Max.Value, h.Unit, h.Max.Time.Local().Format("2006-01-02 03:04 PM"))
+	fmt.Fprintf(w, "  Average:  %.2f%s\n", *h.Average, h.Unit)
+	fmt.Fprintf(w, "\n  %s\n", output.Sparkline(values, sparklineWidth))
+
+	// The latest 10 values
+	fmt.Fprintln(w)
+	latest := h.Points[max(0, len(h.Points)-10):]
+	for _, point := range latest {
+		fmt.Fprintf(w, "  %s  %8.2f%s  %s\n", point.Time.Local().Format("2006-01-02 03:04 PM"), point.Value, h.Unit, point.Source)
+	}
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the history columns.
+func (h History) CSVHeader() []string {
+	return []string{"time", "value", "source"}
+}
+
+// CSVRows returns one row per recorded value.
+func (h History) CSVRows() [][]string {
+	var rows [][]string
+	for _, point := range h.Points {
+		rows = append(rows, []string{point.Time.Format(time.RFC3339), output.FormatFloat(point.Value), point.Source})
+	}
+	return rows
+}
+
+// temperatureHistory returns the temperatures recorded for an address between from and to.
+func (a *app) temperatureHistory(address store.Address, from, to time.Time) (History, error) {
+
+	readings, err := a.store.TemperatureReadings(address.Id, from, to)
+	if err != nil {
+		return History{}, err
+	}
+
+	var points []HistoryPoint
+	for _, reading := range readings {
+		points = append(points, HistoryPoint{Time: reading.RecordedAt, Value: reading.Temperature, Source: reading.Source})
+	}
+
+	return newHistory("Temperatures at "+address.MatchedAddress, "F", from, to, points), nil
+}
+
+// priceHistory returns the prices recorded for a ticker symbol between from and to.
+func (a *app) priceHistory(ticker store.Ticker, from, to time.Time) (History, error) {
+
+	quotes, err := a.store.PriceQuotes(ticker.Ticker, from, to)
+	if err != nil {
+		return History{}, err
+	}
+
+	var points []HistoryPoint
+	for _, quote := range quotes {
+		points = append(points, HistoryPoint{Time: quote.RecordedAt, Value: quote.Price, Source: quote.Source})
+	}
+
+	return newHistory(fmt.Sprintf("%s (%s) prices", ticker.CompanyName, ticker.Ticker), "", from, to, points), nil
+}
+
+// parseDateRange parses a start and end date, e.g. "2024-08-01 2024-08-31". The end
+// date defaults to today and the start date to 30 days before it. The returned range
+// ends at midnight after the end date.
+func parseDateRange(input string) (time.Time, time.Time, error) {
+
+	today := time.Now()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+	from, to := today.AddDate(0, 0, -30), today
+
+	dates := strings.Fields(input)
+	if len(dates) > 2 {
+		return from, to, fmt.Errorf("enter a start and an end date (YYYY-MM-DD)")
+	}
+
+	var err error
+	if len(dates) > 0 {
+		if from, err = time.ParseInLocation("2006-01-02", dates[0], time.Local); err != nil {
+			return from, to, fmt.Errorf("invalid date: %w", err)
+		}
+	}
+	if len(dates) > 1 {
+		if to, err = time.ParseInLocation("2006-01-02", dates[1], time.Local); err != nil {
+			return from, to, fmt.Errorf("invalid date: %w", err)
+		}
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("end date is before start date")
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
@@ -0,0 +1,288 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheHeader is set on responses served by a CacheTransport: "hit" for a fresh
+// cached response, "revalidated" after a 304 Not Modified and "stale" when the
+// API could not be reached and an expired response was used instead.
+const CacheHeader = "X-Polyapi-Cache"
+
+// secretParams are query parameters and JSON body fields that hold API keys. They are
+// removed from cache keys and stored URLs so secrets aren't written to the database.
+var secretParams = []string{"api_key", "apikey", "registrationkey", "client_secret", "access_token", "token"}
+
+// CacheEntry is a cached response.
+type CacheEntry struct {
+	Key          string
+	URL          string
+	ContentType  string
+	Body         []byte
+	ETag         string
+	LastModified string
+	FetchedAt    time.Time
+}
+
+// Cache stores responses by cache key.
+type Cache interface {
+	// Get returns the entry for key; ok is false if there is none.
+	Get(key string) (entry CacheEntry, ok bool, err error)
+	// Put saves the entry, replacing any entry with the same key.
+	Put(entry CacheEntry) error
+}
+
+// CacheMode controls whether a CacheTransport reads and writes the cache.
+type CacheMode int
+
+const (
+	// CacheDefault serves fresh responses from the cache and revalidates expired ones.
+	CacheDefault CacheMode = iota
+	// CacheRefresh always fetches from the API and saves the response in the cache.
+	CacheRefresh
+	// CacheOff neither reads nor writes the cache.
+	CacheOff
+)
+
+type cacheModeKey struct{}
+
+// WithCacheMode returns a context whose requests use mode instead of their
+// CacheTransport's Mode, e.g. to refresh a series once new data is released.
+func WithCacheMode(ctx context.Context, mode CacheMode) context.Context {
+	return context.WithValue(ctx, cacheModeKey{}, mode)
+}
+
+// CacheTransport is an http.RoundTripper that caches successful GET and POST
+// query responses for TTL. Expired responses are revalidated with their ETag or Last-Modified
+// date, and are served stale if the API can't be reached.
+type CacheTransport struct {
+	// Base makes the requests; http.DefaultTransport if nil.
+	Base  http.RoundTripper
+	Cache Cache
+	TTL   time.Duration
+	Mode  CacheMode
+	// Validate, if set, returns an error for a 200 OK body that's really an API error,
+	// such as Alpha Vantage's daily quota message. Such bodies aren't cached.
+	Validate func(body []byte) error
+}
+
+// NewCachedClient returns an HTTP client that caches responses for ttl on top of
+// a retrying Transport.
+func NewCachedClient(cache Cache, ttl time.Duration, mode CacheMode) *http.Client {
+	return &http.Client{Transport: &CacheTransport{Base: &Transport{}, Cache: cache, TTL: ttl, Mode: mode}}
+}
+
+func (t *CacheTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// RoundTrip serves the request from the cache or the API.
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	mode := t.Mode
+	if m, ok := req.Context().Value(cacheModeKey{}).(CacheMode); ok {
+		mode = m
+	}
+
+	if mode == CacheOff || t.TTL <= 0 || t.Cache == nil {
+		return t.base().RoundTrip(req)
+	}
+
+	key, ok := requestKey(req)
+	if !ok {
+		return t.base().RoundTrip(req)
+	}
+
+	entry, cached, err := t.Cache.Get(key)
+	if err != nil {
+		log.Printf("Error reading cache: %v", err)
+		cached = false
+	}
+	// An error cached before it was validated is fetched again
+	if cached && !t.valid(entry.Body) {
+		cached = false
+	}
+
+	if cached && mode == CacheDefault {
+		if time.Since(entry.FetchedAt) < t.TTL {
+			return entry.response(req, "hit"), nil
+		}
+
+		// Ask the API whether the expired response is still current
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := t.base().RoundTrip(req)
+
+	// Keep working offline with the expired response
+	if cached && (err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) {
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		log.Printf("Using cached response from %s: %v", entry.FetchedAt.Local().Format("2006-01-02 03:04 PM"), err)
+		return entry.response(req, "stale"), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		resp.Body.Close()
+		entry.FetchedAt = time.Now()
+		t.put(entry)
+		return entry.response(req, "revalidated"), nil
+
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if !t.valid(body) {
+			break
+		}
+
+		t.put(CacheEntry{
+			Key:          key,
+			URL:          RedactURL(req.URL),
+			ContentType:  resp.Header.Get("Content-Type"),
+			Body:         body,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+		})
+	}
+
+	return resp, nil
+}
+
+// valid reports whether a response body can be cached.
+func (t *CacheTransport) valid(body []byte) bool {
+	return t.Validate == nil || t.Validate(body) == nil
+}
+
+// put saves an entry, logging rather than failing the request if it can't be saved.
+func (t *CacheTransport) put(entry CacheEntry) {
+	if err := t.Cache.Put(entry); err != nil {
+		log.Printf("Error writing cache: %v", err)
+	}
+}
+
+// response returns the cached entry as a 200 OK response to req.
+func (e CacheEntry) response(req *http.Request, status string) *http.Response {
+	header := http.Header{}
+	if e.ContentType != "" {
+		header.Set("Content-Type", e.ContentType)
+	}
+	header.Set(CacheHeader, status)
+	header.Set("Age", strconv.Itoa(int(time.Since(e.FetchedAt).Seconds())))
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// RedactURL returns the URL without the query parameters that hold API keys.
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for name := range query {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				query.Del(name)
+			}
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+// redactBody returns a JSON object body without the fields that hold API keys, such
+// as the BLS registrationkey. Other bodies are returned as they are.
+func redactBody(body []byte) []byte {
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	for name := range fields {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				delete(fields, name)
+			}
+		}
+	}
+
+	// Marshal sorts the fields, so the key doesn't depend on their order either
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// CacheKey returns the cache key for a GET request: the redacted URL with its
+// query parameters sorted, so the same request made with a different API key
+// or parameter order shares a cache entry.
+func CacheKey(u *url.URL) string {
+	return "GET " + RedactURL(u)
+}
+
+// requestKey returns the cache key for a request. Queries sent as POST, like the
+// BLS API's, are keyed by a hash of the body as well, without the API keys in it,
+// so they share a cache entry across API keys too. Other methods aren't cached.
+func requestKey(req *http.Request) (string, bool) {
+
+	switch req.Method {
+	case http.MethodGet:
+		return CacheKey(req.URL), true
+	case http.MethodPost:
+		if req.GetBody == nil {
+			return "", false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return "", false
+		}
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("POST %s %x", RedactURL(req.URL), sha256.Sum256(redactBody(data))), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,193 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryCache is a Cache kept in a map.
+type memoryCache map[string]CacheEntry
+
+func (c memoryCache) Get(key string) (CacheEntry, bool, error) {
+	entry, ok := c[key]
+	return entry, ok, nil
+}
+
+func (c memoryCache) Put(entry CacheEntry) error {
+	c[entry.Key] = entry
+	return nil
+}
+
+// get makes a GET request and returns the body and cache header.
+func get(t *testing.T, client *http.Client, url string) (string, string) {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if err := CheckResponse(resp); err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body), resp.Header.Get(CacheHeader)
+}
+
+func TestCacheTransport(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, `{"value": 1}`)
+	}))
+	defer server.Close()
+
+	cache := memoryCache{}
+	transport := &CacheTransport{Base: server.Client().Transport, Cache: cache, TTL: time.Hour}
+	client := &http.Client{Transport: transport}
+
+	body, status := get(t, client, server.URL+"/series?id=GDP&api_key=one")
+	if body != `{"value": 1}` || status != "" || requests != 1 {
+		t.Fatalf("first request: %q, %q after %d requests", body, status, requests)
+	}
+
+	// The API key isn't part of the key or stored URL, so a different key is a cache hit
+	entry, ok := cache[CacheKey(mustParse(t, server.URL+"/series?api_key=two&id=GDP"))]
+	if !ok || strings.Contains(entry.URL, "api_key") {
+		t.Fatalf("cache = %v", cache)
+	}
+	body, status = get(t, client, server.URL+"/series?api_key=two&id=GDP")
+	if body != `{"value": 1}` || status != "hit" || requests != 1 {
+		t.Errorf("cached request: %q, %q after %d requests", body, status, requests)
+	}
+
+	// An expired response is revalidated with its ETag
+	entry.FetchedAt = time.Now().Add(-2 * time.Hour)
+	cache[entry.Key] = entry
+	body, status = get(t, client, server.URL+"/series?id=GDP")
+	if body != `{"value": 1}` || status != "revalidated" || requests != 2 {
+		t.Errorf("revalidated request: %q, %q after %d requests", body, status, requests)
+	}
+	if time.Since(cache[entry.Key].FetchedAt) > time.Minute {
+		t.Error("revalidation didn't update fetched_at")
+	}
+
+	// --refresh skips the cache
+	transport.Mode = CacheRefresh
+	if _, status = get(t, client, server.URL+"/series?id=GDP"); status != "" || requests != 3 {
+		t.Errorf("refreshed request: %q after %d requests", status, requests)
+	}
+
+	// A request's context can refresh it too
+	transport.Mode = CacheDefault
+	req, err := http.NewRequestWithContext(WithCacheMode(context.Background(), CacheRefresh), "GET", server.URL+"/series?id=GDP", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if status := resp.Header.Get(CacheHeader); status != "" || requests != 4 {
+		t.Errorf("request refreshed by its context: %q after %d requests", status, requests)
+	}
+
+	// Offline, the expired response is served stale
+	entry = cache[entry.Key]
+	entry.FetchedAt = time.Now().Add(-2 * time.Hour)
+	cache[entry.Key] = entry
+	server.Close()
+	body, status = get(t, client, server.URL+"/series?id=GDP")
+	if body != `{"value": 1}` || status != "stale" {
+		t.Errorf("offline request: %q, %q", body, status)
+	}
+}
+
+func TestCacheValidate(t *testing.T) {
+	limited := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limited {
+			io.WriteString(w, `{"error": "limit reached"}`)
+			return
+		}
+		io.WriteString(w, `{"value": 1}`)
+	}))
+	defer server.Close()
+
+	cache := memoryCache{}
+	validate := func(body []byte) error {
+		if strings.Contains(string(body), "error") {
+			return errors.New("API error")
+		}
+		return nil
+	}
+	client := &http.Client{Transport: &CacheTransport{Base: server.Client().Transport, Cache: cache, TTL: time.Hour, Validate: validate}}
+
+	// The error is returned but not cached, so the next request reaches the API
+	if body, _ := get(t, client, server.URL+"/series"); body != `{"error": "limit reached"}` || len(cache) != 0 {
+		t.Fatalf("limited request: %q with %d cached", body, len(cache))
+	}
+	limited = false
+	if body, status := get(t, client, server.URL+"/series"); body != `{"value": 1}` || status != "" || len(cache) != 1 {
+		t.Errorf("request after the limit: %q, %q with %d cached", body, status, len(cache))
+	}
+
+	// An error cached without validation isn't served
+	key := CacheKey(mustParse(t, server.URL+"/other"))
+	cache[key] = CacheEntry{Key: key, Body: []byte(`{"error": "limit reached"}`), FetchedAt: time.Now()}
+	if body, status := get(t, client, server.URL+"/other"); body != `{"value": 1}` || status != "" {
+		t.Errorf("request with an error cached: %q, %q", body, status)
+	}
+}
+
+func TestCacheKeyIncludesPostBody(t *testing.T) {
+	first := httptest.NewRequest("POST", "https://api.bls.gov/publicAPI/v2/timeseries/data/", strings.NewReader(`{"seriesid":["A"]}`))
+	first.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(`{"seriesid":["A"]}`)), nil }
+	second := httptest.NewRequest("POST", "https://api.bls.gov/publicAPI/v2/timeseries/data/", nil)
+	second.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(`{"seriesid":["B"]}`)), nil }
+
+	firstKey, ok := requestKey(first)
+	secondKey, _ := requestKey(second)
+	if !ok || firstKey == secondKey {
+		t.Errorf("keys = %q, %q", firstKey, secondKey)
+	}
+
+	// The registration key isn't part of the key
+	withKey := httptest.NewRequest("POST", "https://api.bls.gov/publicAPI/v2/timeseries/data/", nil)
+	withKey.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(`{"registrationkey":"secret","seriesid":["A"]}`)), nil
+	}
+	if key, _ := requestKey(withKey); key != firstKey {
+		t.Errorf("key with a registration key = %q, want %q", key, firstKey)
+	}
+
+	if _, ok := requestKey(httptest.NewRequest("DELETE", "https://example.com/", nil)); ok {
+		t.Error("DELETE requests shouldn't be cached")
+	}
+}
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
@@ -0,0 +1,251 @@
+// Package httpx is the HTTP layer shared by the polyapi providers. Its
+// Transport gives each attempt a timeout and retries rate limited and server
+// error responses with exponential backoff, and CheckResponse turns error
+// responses into a StatusError with a snippet of the response body.
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is how long a single attempt may take, including reading the body.
+	DefaultTimeout = 30 * time.Second
+	// DefaultMaxRetries is how many times a failed request is retried.
+	DefaultMaxRetries = 3
+	// DefaultBaseDelay is the delay before the first retry; it doubles on each retry.
+	DefaultBaseDelay = 500 * time.Millisecond
+	// DefaultMaxDelay caps the backoff and Retry-After delays.
+	DefaultMaxDelay = 30 * time.Second
+
+	// snippetLength is how much of an error response body is kept in a StatusError.
+	snippetLength = 512
+)
+
+// DefaultClient is the client used by every provider unless another is set.
+var DefaultClient = NewClient()
+
+// NewClient returns an HTTP client with a retrying Transport using the default settings.
+func NewClient() *http.Client {
+	return &http.Client{Transport: &Transport{}}
+}
+
+// Transport is an http.RoundTripper that times out each attempt and retries
+// requests that fail with a network error, 429 Too Many Requests or a 5xx status.
+// Retry-After is honored, up to MaxDelay. Zero values use the defaults.
+type Transport struct {
+	// Base makes the requests; http.DefaultTransport if nil.
+	Base http.RoundTripper
+	// Timeout limits each attempt, including reading the response body.
+	Timeout time.Duration
+	// MaxRetries is the number of retries after the first attempt; negative disables retries.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff and Retry-After delays.
+	MaxDelay time.Duration
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) timeout() time.Duration {
+	if t.Timeout > 0 {
+		return t.Timeout
+	}
+	return DefaultTimeout
+}
+
+func (t *Transport) maxRetries() int {
+	if t.MaxRetries < 0 {
+		return 0
+	}
+	if t.MaxRetries == 0 {
+		return DefaultMaxRetries
+	}
+	return t.MaxRetries
+}
+
+func (t *Transport) baseDelay() time.Duration {
+	if t.BaseDelay > 0 {
+		return t.BaseDelay
+	}
+	return DefaultBaseDelay
+}
+
+func (t *Transport) maxDelay() time.Duration {
+	if t.MaxDelay > 0 {
+		return t.MaxDelay
+	}
+	return DefaultMaxDelay
+}
+
+// RoundTrip sends the request, retrying it while the response is retryable.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	for attempt := 0; ; attempt++ {
+
+		// Requests with a body can only be retried if the body can be read again
+		if attempt > 0 && req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.try(req)
+
+		canRetry := attempt < t.maxRetries() && (req.Body == nil || req.GetBody != nil)
+		if !canRetry || !retryable(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = min(retryAfter, t.maxDelay())
+			}
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// try sends a single attempt with its own timeout. The timeout is cancelled
+// when the response body is closed.
+func (t *Transport) try(req *http.Request) (*http.Response, error) {
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout())
+
+	resp, err := t.base().RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// backoff returns the exponential backoff with full jitter for a retry.
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.baseDelay() << attempt
+	if delay <= 0 || delay > t.maxDelay() {
+		delay = t.maxDelay()
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// retryable reports whether a request should be retried after this response or error.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		// Timeouts and connection errors are retried; a cancelled request is not
+		var netErr net.Error
+		return !errors.Is(err, context.Canceled) && (errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF))
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cancelBody releases the attempt's timeout when the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// StatusError is returned for a response with an unexpected status code.
+// Body holds the start of the response body, which usually explains the error.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s: unexpected status %s", e.Method, e.URL, e.Status)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// CheckResponse returns a StatusError if the response status is not 2xx.
+// The response body is read and closed in that case.
+// Query parameters are left out of the URL in the error since they can hold API keys.
+func CheckResponse(resp *http.Response) error {
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, snippetLength))
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+
+	statusErr := &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       snippet,
+	}
+	if resp.Request != nil {
+		statusErr.Method = resp.Request.Method
+		redacted := *resp.Request.URL
+		redacted.RawQuery = ""
+		statusErr.URL = redacted.String()
+	}
+
+	return statusErr
+}
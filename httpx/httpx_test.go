@@ -0,0 +1,127 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client with fast retries for a server running handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*http.Client, string) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: &Transport{
+		Base:      server.Client().Transport,
+		BaseDelay: time.Millisecond,
+		MaxDelay:  5 * time.Millisecond,
+	}}
+	return client, server.URL
+}
+
+func TestRetriesServerErrors(t *testing.T) {
+	attempts := 0
+	client, url := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d body = %q", attempts, body)
+		}
+		switch attempts {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		case 2:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			io.WriteString(w, "ok")
+		}
+	})
+
+	resp, err := client.Post(url, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" || attempts != 3 {
+		t.Errorf("got %d %q after %d attempts", resp.StatusCode, body, attempts)
+	}
+}
+
+func TestDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	client, url := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, `{"error": "bad series id"}`, http.StatusBadRequest)
+	})
+
+	resp, err := client.Get(url + "/series?api_key=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+
+	err = CheckResponse(resp)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("error = %v, want a StatusError", err)
+	}
+	if statusErr.StatusCode != http.StatusBadRequest || statusErr.Body != `{"error": "bad series id"}` {
+		t.Errorf("StatusError = %+v", statusErr)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks the query string: %v", err)
+	}
+}
+
+func TestGivesUpAfterMaxRetries(t *testing.T) {
+	attempts := 0
+	client, url := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, strings.Repeat("x", 2*snippetLength), http.StatusBadGateway)
+	})
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != DefaultMaxRetries+1 {
+		t.Errorf("got %d attempts, want %d", attempts, DefaultMaxRetries+1)
+	}
+
+	var statusErr *StatusError
+	if !errors.As(CheckResponse(resp), &statusErr) || len(statusErr.Body) != snippetLength {
+		t.Errorf("StatusError = %+v", statusErr)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 8, 26, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"Mon, 26 Aug 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Mon, 26 Aug 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	} {
+		got, ok := parseRetryAfter(tc.value, now)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tc.value, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"polyapi/bls"
+	"polyapi/store"
+)
+
+// localCommand prints the unemployment rates and CPI of the state and metro area of
+// an address, e.g. `polyapi local --address "432 Park Ave, 10022"`.
+func (a *app) localCommand(ctx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("local", flag.ContinueOnError)
+	address := flags.String("address", "", "street address to geocode, e.g. \"432 Park Ave, 10022\"")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Allow the address to be passed as plain arguments as well
+	if *address == "" {
+		*address = strings.Join(flags.Args(), " ")
+	}
+	if *address == "" {
+		return fmt.Errorf("local requires --address")
+	}
+
+	saved, err := a.weather.SaveAddress(ctx, *address)
+	if err != nil {
+		return err
+	}
+
+	report, err := a.localIndicators(ctx, saved)
+	if err != nil {
+		return err
+	}
+
+	return render(report)
+}
+
+// localIndicators looks up the state and metro area of a saved address, if it wasn't
+// before, and summarizes their BLS unemployment rates and CPI.
+func (a *app) localIndicators(ctx context.Context, address store.Address) (bls.Report, error) {
+
+	located, err := a.weather.Locate(ctx, address)
+	if err != nil {
+		return bls.Report{}, err
+	}
+
+	return a.bls.Regional(ctx, located.Region)
+}
@@ -0,0 +1,200 @@
+// Package metrics publishes the latest stored values, such as the temperature of each
+// saved address, and the health of each provider's API in the Prometheus text format,
+// so they can be scraped and alerted on.
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Family is a metric with its samples, e.g. the temperature of each address.
+// Type is gauge, counter or histogram.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Sample is a value of a metric. Labels are name and value pairs, e.g.
+// {"ticker", "AAPL"}, and Suffix is appended to the metric's name, e.g. _bucket.
+type Sample struct {
+	Suffix string
+	Labels []string
+	Value  float64
+}
+
+// Write writes the families in the Prometheus text exposition format.
+func Write(w io.Writer, families []Family) error {
+
+	b := bufio.NewWriter(w)
+	for _, f := range families {
+		b.WriteString("# HELP " + f.Name + " " + f.Help + "\n")
+		b.WriteString("# TYPE " + f.Name + " " + f.Type + "\n")
+		for _, sample := range f.Samples {
+			b.WriteString(f.Name + sample.Suffix)
+			if len(sample.Labels) > 0 {
+				b.WriteString("{")
+				for i := 0; i+1 < len(sample.Labels); i += 2 {
+					if i > 0 {
+						b.WriteString(",")
+					}
+					b.WriteString(sample.Labels[i] + `="` + escapeLabel(sample.Labels[i+1]) + `"`)
+				}
+				b.WriteString("}")
+			}
+			b.WriteString(" " + formatValue(sample.Value) + "\n")
+		}
+	}
+
+	return b.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes backslashes, quotes and newlines in a label value.
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// LatencyBuckets are the upper bounds, in seconds, of the API request latency histogram.
+var LatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// API counts the requests each provider sends to its API, their latency and errors,
+// and the requests Alpha Vantage refused for the daily quota. The zero value is ready
+// to use and it's safe for concurrent use.
+type API struct {
+	mu        sync.Mutex
+	providers map[string]*apiStats
+	quotaHits int
+}
+
+type apiStats struct {
+	requests int
+	errors   int
+	// buckets counts the requests that took up to each of LatencyBuckets
+	buckets []int
+	seconds float64
+}
+
+// Observe records a request to a provider's API that took duration. failed is true
+// when the request failed or the API answered with an error.
+func (a *API) Observe(provider string, duration time.Duration, failed bool) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.providers == nil {
+		a.providers = make(map[string]*apiStats)
+	}
+	stats, ok := a.providers[provider]
+	if !ok {
+		stats = &apiStats{buckets: make([]int, len(LatencyBuckets))}
+		a.providers[provider] = stats
+	}
+
+	stats.requests++
+	if failed {
+		stats.errors++
+	}
+	seconds := duration.Seconds()
+	stats.seconds += seconds
+	for i, bound := range LatencyBuckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+		}
+	}
+}
+
+// QuotaHit records a request refused because the Alpha Vantage daily quota is used up.
+func (a *API) QuotaHit() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.quotaHits++
+}
+
+// roundTripperFunc adapts a function to an http.RoundTripper.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// Transport returns a RoundTripper that records each request base sends for provider.
+// Network errors, responses with a 4xx or 5xx status and 200 OK bodies that check
+// rejects, such as Alpha Vantage's quota message, count as errors; check may be nil.
+// Use it as the base of the retrying httpx.Transport, so each attempt is recorded and
+// the backoff between attempts isn't counted as latency.
+func (a *API) Transport(provider string, base http.RoundTripper, check func(body []byte) error) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+
+		start := time.Now()
+		resp, err := base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusOK || check == nil {
+			a.Observe(provider, time.Since(start), err != nil || resp.StatusCode >= 400)
+			return resp, err
+		}
+
+		// The body is read to check it, so its latency is included
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		a.Observe(provider, time.Since(start), err != nil || check(body) != nil)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	})
+}
+
+// Families returns the request counts, errors and latency histogram of each provider,
+// and the quota hits.
+func (a *API) Families() []Family {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	names := make([]string, 0, len(a.providers))
+	for name := range a.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	requests := Family{Name: "polyapi_api_requests_total", Help: "Requests sent to each provider's API, counting each retry.", Type: "counter"}
+	errors := Family{Name: "polyapi_api_errors_total", Help: "Requests to each provider's API that failed or got an error response.", Type: "counter"}
+	latency := Family{Name: "polyapi_api_request_duration_seconds", Help: "Latency of the requests to each provider's API.", Type: "histogram"}
+
+	for _, name := range names {
+		stats := a.providers[name]
+		labels := []string{"provider", name}
+		requests.Samples = append(requests.Samples, Sample{Labels: labels, Value: float64(stats.requests)})
+		errors.Samples = append(errors.Samples, Sample{Labels: labels, Value: float64(stats.errors)})
+		for i, bound := range LatencyBuckets {
+			latency.Samples = append(latency.Samples, Sample{Suffix: "_bucket", Labels: []string{"provider", name, "le", formatValue(bound)}, Value: float64(stats.buckets[i])})
+		}
+		latency.Samples = append(latency.Samples,
+			Sample{Suffix: "_bucket", Labels: []string{"provider", name, "le", "+Inf"}, Value: float64(stats.requests)},
+			Sample{Suffix: "_sum", Labels: labels, Value: stats.seconds},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(stats.requests)},
+		)
+	}
+
+	quotaHits := Family{Name: "polyapi_alphavantage_quota_hits_total", Help: "Alpha Vantage requests refused because the daily quota was used up.", Type: "counter",
+		Samples: []Sample{{Value: float64(a.quotaHits)}}}
+
+	return []Family{requests, errors, latency, quotaHits}
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"polyapi/httpx"
+	"polyapi/store"
+)
+
+// written returns the families in the text format.
+func written(t *testing.T, families []Family) string {
+	t.Helper()
+	var b strings.Builder
+	if err := Write(&b, families); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+// checkLines reports each line missing from text.
+func checkLines(t *testing.T, text string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains("\n"+text, "\n"+line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	text := written(t, []Family{{
+		Name: "polyapi_temperature_fahrenheit", Help: "Latest temperature.", Type: "gauge",
+		Samples: []Sample{{Labels: []string{"address", `1 "A" St\Apt 2`}, Value: 75.9}},
+	}})
+
+	want := "# HELP polyapi_temperature_fahrenheit Latest temperature.\n" +
+		"# TYPE polyapi_temperature_fahrenheit gauge\n" +
+		`polyapi_temperature_fahrenheit{address="1 \"A\" St\\Apt 2"} 75.9` + "\n"
+	if text != want {
+		t.Errorf("Write() = %q, want %q", text, want)
+	}
+}
+
+func TestAPI(t *testing.T) {
+	var attempts int
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/quota":
+			io.WriteString(w, `{"Information": "daily limit"}`)
+		case "/flaky":
+			// The first attempt fails and is retried
+			if attempts++; attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}
+	}))
+	defer api.Close()
+
+	var stats API
+	check := func(body []byte) error {
+		if strings.Contains(string(body), "Information") {
+			return errors.New("quota exceeded")
+		}
+		return nil
+	}
+	client := &http.Client{Transport: &httpx.Transport{Base: stats.Transport("espn", http.DefaultTransport, check), BaseDelay: time.Millisecond}}
+	for _, path := range []string{"/scoreboard", "/missing", "/quota", "/flaky"} {
+		resp, err := client.Get(api.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	stats.Observe("fred", 3*time.Second, true)
+	stats.QuotaHit()
+
+	checkLines(t, written(t, stats.Families()),
+		`polyapi_api_requests_total{provider="espn"} 5`,
+		`polyapi_api_errors_total{provider="espn"} 3`,
+		`polyapi_api_requests_total{provider="fred"} 1`,
+		`polyapi_api_errors_total{provider="fred"} 1`,
+		`polyapi_api_request_duration_seconds_bucket{provider="fred",le="2.5"} 0`,
+		`polyapi_api_request_duration_seconds_bucket{provider="fred",le="5"} 1`,
+		`polyapi_api_request_duration_seconds_bucket{provider="fred",le="+Inf"} 1`,
+		`polyapi_api_request_duration_seconds_sum{provider="fred"} 3`,
+		`polyapi_api_request_duration_seconds_count{provider="espn"} 5`,
+		`polyapi_alphavantage_quota_hits_total 1`,
+	)
+}
+
+func TestValues(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	address, err := s.SaveAddress("432 PARK AVE, NEW YORK, NY, 10022", 40.76, -73.97)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordedAt := time.Date(2024, 8, 26, 14, 51, 0, 0, time.UTC)
+	if err := s.AddTemperatureReading(store.TemperatureReading{AddressId: address.Id, Temperature: 75.9, Source: "KNYC", RecordedAt: recordedAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPriceQuote(store.PriceQuote{Ticker: "AAPL", Price: 227.18, TradingDay: "2024-08-26", Source: "alphavantage", RecordedAt: recordedAt}); err != nil {
+		t.Fatal(err)
+	}
+	curve := store.YieldCurve{Date: "2024-08-26", Yields: []store.Yield{{Maturity: "3M", Yield: 5.28}, {Maturity: "2Y", Yield: 3.93}, {Maturity: "10Y", Yield: 3.82}}}
+	if err := s.SaveYieldCurves([]store.YieldCurve{curve}); err != nil {
+		t.Fatal(err)
+	}
+	rates := store.TreasuryRates{RecordDate: "2024-07-31", Securities: []store.SecurityRate{
+		{Type: "Marketable", Security: "Treasury Bills", Rate: 5.346},
+		{Type: "Marketable", Security: "Treasury Notes", Rate: 2.818},
+		{Type: "Marketable", Security: "Treasury Bonds", Rate: 3.196},
+	}}
+	if err := s.SaveTreasuryRates(rates); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveObservations([]store.Observation{{Source: "fred", SeriesID: "FEDFUNDS", Date: "2024-07-01", Value: 5.33}}); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := Values(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := written(t, families)
+
+	checkLines(t, text,
+		`polyapi_temperature_fahrenheit{address="432 PARK AVE, NEW YORK, NY, 10022"} 75.9`,
+		`polyapi_stock_price{ticker="AAPL"} 227.18`,
+		`polyapi_treasury_yield_percent{maturity="10Y"} 3.82`,
+		`polyapi_treasury_spread_percent{spread="2s10s"} -0.11`,
+		`polyapi_treasury_curve_inverted 1`,
+		`polyapi_treasury_rate_percent{type="Marketable",security="Treasury Notes"} 2.818`,
+		`polyapi_treasury_rate_spread_percent{spread="bond_note"} 0.378`,
+	)
+	// Series without observations have no samples
+	if n := strings.Count(text, "\npolyapi_series_value{"); n != 1 || !strings.Contains(text, `series="FEDFUNDS"`) {
+		t.Errorf("%d series values, want FEDFUNDS only:\n%s", n, text)
+	}
+}
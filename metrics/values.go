@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"polyapi/store"
+	"polyapi/treasury"
+)
+
+// Values returns the latest stored values as gauges: the temperature of each saved
+// address, the price of each ticker symbol, the Treasury yields, average interest rates
+// and their spreads, and each series on the FRED and BLS watchlists. They're as fresh as the last time they were
+// fetched, by a command, the TUI, the server or serve --update.
+func Values(s *store.Store) ([]Family, error) {
+
+	temperatures := Family{Name: "polyapi_temperature_fahrenheit", Help: "Latest temperature recorded for each saved address.", Type: "gauge"}
+	addresses, err := s.Addresses()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(addresses))
+	for _, address := range addresses {
+		names[address.Id] = address.MatchedAddress
+	}
+	readings, err := s.LatestTemperatureReadings()
+	if err != nil {
+		return nil, err
+	}
+	for _, reading := range readings {
+		if name, ok := names[reading.AddressId]; ok {
+			temperatures.Samples = append(temperatures.Samples, Sample{Labels: []string{"address", name}, Value: reading.Temperature})
+		}
+	}
+
+	prices := Family{Name: "polyapi_stock_price", Help: "Latest price quoted for each ticker symbol.", Type: "gauge"}
+	quotes, err := s.LatestPriceQuotes()
+	if err != nil {
+		return nil, err
+	}
+	for _, quote := range quotes {
+		prices.Samples = append(prices.Samples, Sample{Labels: []string{"ticker", quote.Ticker}, Value: quote.Price})
+	}
+
+	yields := Family{Name: "polyapi_treasury_yield_percent", Help: "Treasury par yield of each maturity on the latest stored curve.", Type: "gauge"}
+	spreads := Family{Name: "polyapi_treasury_spread_percent", Help: "Treasury yield spreads on the latest stored curve.", Type: "gauge"}
+	inverted := Family{Name: "polyapi_treasury_curve_inverted", Help: "1 if a spread of the latest stored yield curve is negative.", Type: "gauge"}
+	curve, ok, err := treasury.StoredYieldCurve(s)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		for _, rate := range curve.Yields {
+			yields.Samples = append(yields.Samples, Sample{Labels: []string{"maturity", rate.Name}, Value: rate.Value})
+		}
+		for _, rate := range curve.Spreads {
+			spreads.Samples = append(spreads.Samples, Sample{Labels: []string{"spread", rate.Name}, Value: rate.Value})
+		}
+		value := 0.0
+		if curve.Inverted {
+			value = 1
+		}
+		inverted.Samples = append(inverted.Samples, Sample{Value: value})
+	}
+
+	rates := Family{Name: "polyapi_treasury_rate_percent", Help: "Average interest rate of each Treasury security in the latest stored month.", Type: "gauge"}
+	rateSpreads := Family{Name: "polyapi_treasury_rate_spread_percent", Help: "Spreads between the average interest rates of Treasury bonds, notes and bills in the latest stored month.", Type: "gauge"}
+	averages, ok, err := treasury.StoredRates(s)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		for _, security := range averages.Securities {
+			rates.Samples = append(rates.Samples, Sample{Labels: []string{"type", security.Type, "security", security.Security}, Value: security.Rate})
+		}
+		rateSpreads.Samples = append(rateSpreads.Samples,
+			Sample{Labels: []string{"spread", "bond_bill"}, Value: averages.BondBillSpread},
+			Sample{Labels: []string{"spread", "note_bill"}, Value: averages.NoteBillSpread},
+			Sample{Labels: []string{"spread", "bond_note"}, Value: averages.BondNoteSpread},
+		)
+	}
+
+	series := Family{Name: "polyapi_series_value", Help: "Latest observation of each series on the FRED and BLS watchlists.", Type: "gauge"}
+	watchlist, err := s.Watchlist("")
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range watchlist {
+		observation, ok, err := s.LatestObservation(w.Source, w.SeriesID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			labels := []string{"source", w.Source, "series", w.SeriesID, "label", w.Label}
+			series.Samples = append(series.Samples, Sample{Labels: labels, Value: observation.Value})
+		}
+	}
+
+	return []Family{temperatures, prices, yields, spreads, inverted, rates, rateSpreads, series}, nil
+}
@@ -0,0 +1,32 @@
+package output
+
+// Colors enables ANSI colors in table output. The CLI turns it on when stdout is a
+// terminal and NO_COLOR isn't set.
+var Colors bool
+
+// ANSI color codes used in table output.
+const (
+	Bold   = "1"
+	Red    = "31"
+	Green  = "32"
+	Yellow = "33"
+)
+
+// Color wraps text in an ANSI color when colors are enabled.
+func Color(color, text string) string {
+	if !Colors {
+		return text
+	}
+	return "\x1b[" + color + "m" + text + "\x1b[0m"
+}
+
+// ColorChange colors text green for a rise and red for a fall.
+func ColorChange(change float64, text string) string {
+	switch {
+	case change > 0:
+		return Color(Green, text)
+	case change < 0:
+		return Color(Red, text)
+	}
+	return text
+}
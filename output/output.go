@@ -0,0 +1,151 @@
+// Package output renders provider results as a table, JSON or CSV.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is a typed provider result that can be rendered in any output format.
+type Result interface {
+	// PrintTable writes the human-readable output shown in the terminal UI.
+	PrintTable(w io.Writer)
+	// CSVHeader returns the column names for CSV output.
+	CSVHeader() []string
+	// CSVRows returns one row per record for CSV output.
+	CSVRows() [][]string
+}
+
+// ValidFormat reports whether format is a supported output format.
+func ValidFormat(format string) bool {
+	switch format {
+	case "table", "json", "csv":
+		return true
+	}
+	return false
+}
+
+// Write writes a result to w as a table, JSON or CSV.
+func Write(w io.Writer, format string, result Result) error {
+
+	switch format {
+	case "table":
+		result.PrintTable(w)
+		return nil
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(result.CSVHeader()); err != nil {
+			return err
+		}
+		return writer.WriteAll(result.CSVRows())
+	default:
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+}
+
+// FormatFloat formats a number for CSV output.
+func FormatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// FormatOptional formats an optional number for CSV output, leaving it blank when missing.
+func FormatOptional(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return FormatFloat(*value)
+}
+
+// timestampFormats are the timestamp layouts returned by the APIs and the database,
+// with and without timezone offset.
+var timestampFormats = []string{
+	time.RFC3339,          // e.g., "2024-08-26T14:25:00+00:00"
+	"2006-01-02T15:04:05", // e.g., "2024-08-26T14:25:00" (without offset)
+}
+
+// ExtractDate extracts the date from a timestamp, handling both with and without timezone offset.
+func ExtractDate(timestamp string) string {
+	if timestamp == "" {
+		return "Unknown Date"
+	}
+
+	// Try parsing with each format
+	for _, format := range timestampFormats {
+		t, err := time.Parse(format, timestamp)
+		if err == nil {
+			return t.Format("2006-01-02") // Successfully parsed, return formatted date
+		}
+	}
+
+	return "Unknown Date"
+}
+
+// FormatTime formats the time part of the timestamp.
+func FormatTime(timestamp string) string {
+	if timestamp == "" {
+		return "Unknown Time"
+	}
+
+	// Try parsing with each format
+	for _, format := range timestampFormats {
+		t, err := time.Parse(format, timestamp)
+		if err == nil {
+			return t.Format("03:04 PM") // Format time as "03:04 PM"
+		}
+	}
+
+	return "Unknown Time"
+}
+
+// sparkTicks are the bar heights of a sparkline, lowest to highest.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a line of bars scaled between their min and max.
+// If there are more values than width, consecutive values are averaged.
+func Sparkline(values []float64, width int) string {
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	// Average consecutive values into at most width buckets
+	if width > 0 && len(values) > width {
+		buckets := make([]float64, width)
+		for i := range buckets {
+			start := i * len(values) / width
+			end := (i + 1) * len(values) / width
+			var sum float64
+			for _, value := range values[start:end] {
+				sum += value
+			}
+			buckets[i] = sum / float64(end-start)
+		}
+		values = buckets
+	}
+
+	low, high := values[0], values[0]
+	for _, value := range values {
+		low = min(low, value)
+		high = max(high, value)
+	}
+
+	var line strings.Builder
+	for _, value := range values {
+		tick := len(sparkTicks) / 2
+		if high > low {
+			tick = int((value - low) / (high - low) * float64(len(sparkTicks)-1))
+		}
+		line.WriteRune(sparkTicks[tick])
+	}
+
+	return line.String()
+}
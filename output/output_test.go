@@ -0,0 +1,36 @@
+package output
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	for _, tc := range []struct {
+		values []float64
+		width  int
+		want   string
+	}{
+		{nil, 10, ""},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8}, 10, "▁▂▃▄▅▆▇█"},
+		{[]float64{5, 5, 5}, 10, "▅▅▅"},
+		{[]float64{0, 0, 10, 10}, 2, "▁█"},
+		{[]float64{72, 68.5, 75}, 0, "▄▁█"},
+	} {
+		if got := Sparkline(tc.values, tc.width); got != tc.want {
+			t.Errorf("Sparkline(%v, %d) = %q, want %q", tc.values, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestColor(t *testing.T) {
+	if got := ColorChange(1.5, "+1.50"); got != "+1.50" {
+		t.Errorf("without colors = %q", got)
+	}
+
+	Colors = true
+	defer func() { Colors = false }()
+	if got := ColorChange(-1.5, "-1.50"); got != "\x1b[31m-1.50\x1b[0m" {
+		t.Errorf("fall = %q", got)
+	}
+	if got := ColorChange(0, "0.00"); got != "0.00" {
+		t.Errorf("no change = %q", got)
+	}
+}
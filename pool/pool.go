@@ -0,0 +1,49 @@
+// Package pool fans out work, such as one API request per series or weather station,
+// to a bounded number of goroutines.
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// MaxSize is the most requests providers send to an API at once. It's enough for the
+// FRED dashboard's watchlist or an address's weather stations to be fetched in about
+// one round trip, while staying well within the APIs' rate limits, such as FRED's
+// 120 requests a minute.
+const MaxSize = 10
+
+// Size returns the pool size for n requests: one call per request, up to MaxSize.
+func Size(n int) int {
+	return min(max(n, 1), MaxSize)
+}
+
+// Run calls fn with each index from 0 to n-1, at most size calls at a time, and waits
+// for the calls to finish. fn should store its result at its index so results keep
+// their order. Once ctx is done no more calls are started and its error is returned.
+func Run(ctx context.Context, size, n int, fn func(ctx context.Context, i int)) error {
+
+	sem := make(chan struct{}, max(size, 1))
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		// Both may be ready, so check ctx again before starting the call
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(ctx, i)
+		}()
+	}
+
+	wg.Wait()
+	return ctx.Err()
+}
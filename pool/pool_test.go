@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	results := make([]int, 10)
+
+	err := Run(context.Background(), 3, len(results), func(ctx context.Context, i int) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		// Later calls finish first, but the results keep their order
+		time.Sleep(time.Duration(len(results)-i) * time.Millisecond)
+		results[i] = i * i
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, result := range results {
+		if result != i*i {
+			t.Errorf("results[%d] = %d, want %d", i, result, i*i)
+		}
+	}
+	if maxRunning.Load() > 3 {
+		t.Errorf("%d calls ran at once, want at most 3", maxRunning.Load())
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls atomic.Int32
+	err := Run(ctx, 1, 10, func(ctx context.Context, i int) {
+		calls.Add(1)
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("%d calls, want 1 before the cancellation", calls.Load())
+	}
+}
+
+func TestSize(t *testing.T) {
+	for n, want := range map[int]int{0: 1, 3: 3, MaxSize: MaxSize, 40: MaxSize} {
+		if got := Size(n); got != want {
+			t.Errorf("Size(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
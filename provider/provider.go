@@ -0,0 +1,136 @@
+// Package provider defines the interface shared by every polyapi data provider
+// and a registry the CLI uses to dispatch commands to them.
+package provider
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"polyapi/output"
+)
+
+// Provider is a data source that polyapi can fetch from, e.g. NOAA weather or FRED.
+type Provider interface {
+	// Name is the command name used to run the provider, e.g. "weather".
+	Name() string
+	// Description is a one-line summary shown in the command list.
+	Description() string
+	// RequiredConfig lists the environment variables the provider needs.
+	RequiredConfig() []string
+	// Fetch parses the command arguments and returns the result to render.
+	Fetch(ctx context.Context, args []string) (output.Result, error)
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+)
+
+// Register adds a provider to the registry, replacing any provider with the same name.
+func Register(p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[p.Name()] = p
+}
+
+// Lookup returns the registered provider with the given name.
+func Lookup(name string) (Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[name]
+	return p, ok
+}
+
+// All returns the registered providers sorted by name.
+func All() []Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	all := make([]Provider, 0, len(providers))
+	for _, p := range providers {
+		all = append(all, p)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// CheckConfig returns an error listing the required environment variables that are not set.
+func CheckConfig(p Provider) error {
+	var missing []string
+	for _, name := range p.RequiredConfig() {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required environment variables for %s: %s", p.Name(), strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// UsageError is a mistake in a command's arguments, such as an unknown flag or an
+// invalid date, rather than a failure to fetch the data.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// Usagef returns a UsageError with a formatted message.
+func Usagef(format string, a ...any) error {
+	return &UsageError{Err: fmt.Errorf(format, a...)}
+}
+
+type flagOutputKey struct{}
+
+// WithFlagOutput returns a context whose commands print their flags' usage and parse
+// errors to w instead of stderr, e.g. io.Discard for the server, which answers with
+// the error instead.
+func WithFlagOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, flagOutputKey{}, w)
+}
+
+// ParseFlags parses args with flags, printing any usage to the context's flag output,
+// stderr by default. Errors are UsageErrors, which wrap flag.ErrHelp for -h.
+func ParseFlags(ctx context.Context, flags *flag.FlagSet, args []string) error {
+	var w io.Writer = os.Stderr
+	if output, ok := ctx.Value(flagOutputKey{}).(io.Writer); ok {
+		w = output
+	}
+	flags.SetOutput(w)
+	if err := flags.Parse(args); err != nil {
+		return &UsageError{Err: err}
+	}
+	return nil
+}
+
+// ParseInterspersed parses flags given before, between or after the plain
+// arguments and returns the plain arguments. Arguments after -- are all plain.
+func ParseInterspersed(ctx context.Context, flags *flag.FlagSet, args []string) ([]string, error) {
+
+	var words []string
+	for {
+		if err := ParseFlags(ctx, flags, args); err != nil {
+			return nil, err
+		}
+		// Parse stops after --, which it drops
+		if parsed := len(args) - flags.NArg(); parsed > 0 && args[parsed-1] == "--" {
+			return append(words, flags.Args()...), nil
+		}
+		if flags.NArg() == 0 {
+			return words, nil
+		}
+		words = append(words, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
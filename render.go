@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+
+	"polyapi/output"
+)
+
+// outputFormat is the format results are rendered in: table, json or csv.
+var outputFormat = "table"
+
+// render writes a provider result to stdout in the selected output format.
+func render(result output.Result) error {
+	return output.Write(os.Stdout, outputFormat, result)
+}
+
+// useColors reports whether table output should be colored: stdout is a terminal
+// and NO_COLOR isn't set.
+func useColors() bool {
+	if outputFormat != "table" || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
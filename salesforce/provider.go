@@ -0,0 +1,70 @@
+package salesforce
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+
+	"polyapi/output"
+	"polyapi/provider"
+)
+
+// Provider searches contacts and counts objects in the deployment set in the environment.
+type Provider struct {
+	// HTTPClient makes the requests; httpx.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// NewProvider returns a Salesforce provider.
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+func (p *Provider) Name() string { return "sf" }
+
+func (p *Provider) Description() string {
+	return "Salesforce contacts (sf contacts --filter TEXT) and object counts (sf counts)"
+}
+
+func (p *Provider) RequiredConfig() []string { return RequiredVars }
+
+// Fetch runs the contacts or counts subcommand.
+func (p *Provider) Fetch(ctx context.Context, args []string) (output.Result, error) {
+
+	if len(args) == 0 {
+		return nil, provider.Usagef("sf requires a subcommand: contacts or counts")
+	}
+
+	flags := flag.NewFlagSet("sf "+args[0], flag.ContinueOnError)
+	filter := flags.String("filter", "", "contact first, last name, email or account name filter")
+	if err := provider.ParseFlags(ctx, flags, args[1:]); err != nil {
+		return nil, err
+	}
+
+	client, err := FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if p.HTTPClient != nil {
+		client.HTTPClient = p.HTTPClient
+	}
+
+	_, err = client.GetAccessToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting access token: %w", err)
+	}
+
+	switch args[0] {
+	case "contacts":
+		contacts, err := client.Contacts(ctx, *filter)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving contacts: %w", err)
+		}
+		return contacts, nil
+	case "counts":
+		return client.ObjectCounts(ctx), nil
+	default:
+		return nil, provider.Usagef("unknown sf subcommand: %s", args[0])
+	}
+}
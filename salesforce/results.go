@@ -0,0 +1,74 @@
+package salesforce
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ContactList is the result of a contact search.
+type ContactList []Contact
+
+// PrintTable prints each contact with its account and description.
+func (c ContactList) PrintTable(w io.Writer) {
+
+	if len(c) == 0 {
+		fmt.Fprintln(w, "\nNo contacts found.")
+		return
+	}
+
+	for _, contact := range c {
+		fmt.Fprintf(w, "\nContact Name: %s, %s\nAccount: %s\nEmail: %s\nPhone: %s\nDescription:\n\n%s\n\n", contact.LastName, contact.FirstName, contact.Account.Name, contact.Email, contact.Phone, contact.Description)
+	}
+}
+
+// CSVHeader returns the contact columns.
+func (c ContactList) CSVHeader() []string {
+	return []string{"id", "first_name", "last_name", "account", "email", "phone", "description"}
+}
+
+// CSVRows returns one row per contact.
+func (c ContactList) CSVRows() [][]string {
+	var rows [][]string
+	for _, contact := range c {
+		rows = append(rows, []string{contact.Id, contact.FirstName, contact.LastName, contact.Account.Name, contact.Email, contact.Phone, contact.Description})
+	}
+	return rows
+}
+
+// ObjectCount is the number of records of a Salesforce object.
+type ObjectCount struct {
+	Object string `json:"object"`
+	Count  int    `json:"count"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ObjectCounts are the record counts of a deployment.
+type ObjectCounts []ObjectCount
+
+// PrintTable prints the count of each object.
+func (c ObjectCounts) PrintTable(w io.Writer) {
+	fmt.Fprintln(w, "\nDeployment counts:")
+	fmt.Fprintln(w)
+	for _, count := range c {
+		if count.Error != "" {
+			fmt.Fprintf(w, "Error retrieving count for %s: %s\n", count.Object, count.Error)
+			continue
+		}
+		fmt.Fprintf(w, "  %s: %d\n", count.Object, count.Count)
+	}
+}
+
+// CSVHeader returns the count columns.
+func (c ObjectCounts) CSVHeader() []string {
+	return []string{"object", "count", "error"}
+}
+
+// CSVRows returns one row per object.
+func (c ObjectCounts) CSVRows() [][]string {
+	var rows [][]string
+	for _, count := range c {
+		rows = append(rows, []string{count.Object, strconv.Itoa(count.Count), count.Error})
+	}
+	return rows
+}
@@ -0,0 +1,236 @@
+// Package salesforce queries a Salesforce.com deployment with SOQL using
+// an OAuth access token from the client credentials flow.
+package salesforce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"polyapi/httpx"
+)
+
+const salesforceAPIBaseURL = "/services/data/v54.0"
+
+// RequiredVars are the environment variables with the deployment URL and connected app credentials.
+var RequiredVars = []string{"SALESFORCE_URL_1", "SALESFORCE_CONSUMER_KEY_1", "SALESFORCE_CONSUMER_SECRET_1"}
+
+// Client is a Salesforce deployment with its connected app credentials and access token.
+// Url is the instance URL, e.g. https://example.my.salesforce.com.
+type Client struct {
+	Url            string
+	ConsumerKey    string
+	ConsumerSecret string
+	AccessToken    string
+	HTTPClient     *http.Client
+}
+
+// Define a Contact struct
+type Contact struct {
+	Id          string  `json:"Id"`
+	FirstName   string  `json:"FirstName"`
+	LastName    string  `json:"LastName"`
+	Account     Account `json:"Account"`
+	Email       string  `json:"Email"`
+	Phone       string  `json:"Phone"`
+	Description string  `json:"Description"`
+}
+
+// Define an Account struct
+type Account struct {
+	Id          string `json:"Id"`
+	Name        string `json:"Name"`
+	Type        string `json:"Type"`
+	Description string `json:"Description"`
+	Website     string `json:"Website"`
+	Industry    string `json:"Industry"`
+}
+
+// FromEnv returns a client for the deployment set in the environment.
+func FromEnv() (*Client, error) {
+
+	var missingVars []string
+	values := make([]string, len(RequiredVars))
+
+	for i, varName := range RequiredVars {
+		values[i] = os.Getenv(varName)
+		if values[i] == "" {
+			missingVars = append(missingVars, varName)
+		}
+	}
+
+	if len(missingVars) > 0 {
+		return nil, fmt.Errorf("missing required environment variables for deployment: %s", strings.Join(missingVars, ", "))
+	}
+
+	return &Client{Url: values[0], ConsumerKey: values[1], ConsumerSecret: values[2], HTTPClient: httpx.DefaultClient}, nil
+}
+
+// IsValid checks if the deployment has valid credentials
+func (s *Client) IsValid() bool {
+	return s.Url != "" && s.ConsumerKey != "" && s.ConsumerSecret != ""
+}
+
+// GetAccessToken gets a new OAuth access token with the client credentials flow.
+func (s *Client) GetAccessToken(ctx context.Context) (string, error) {
+
+	form := url.Values{}
+	form.Add("grant_type", "client_credentials")
+	form.Add("client_id", s.ConsumerKey)
+	form.Add("client_secret", s.ConsumerSecret)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.Url+"/services/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for successful response status code
+	if err := httpx.CheckResponse(resp); err != nil {
+		return "", err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response body: %w", err)
+	}
+
+	var result map[string]interface{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return "", fmt.Errorf("error parsing JSON response: %w, response body: %s", err, string(body))
+	}
+
+	accessToken, ok := result["access_token"].(string)
+	if !ok {
+		return "", fmt.Errorf("couldn't parse access token, response body: %s", string(body))
+	}
+
+	s.AccessToken = accessToken
+
+	return accessToken, nil
+
+}
+
+// Query executes SOQL queries
+//
+// Requires:
+//   - Client with access token
+//   - A string with the SOQL query
+//   - A destination interface to store the query results
+func (s *Client) Query(ctx context.Context, soql string, dest interface{}) error {
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, "GET", s.Url+salesforceAPIBaseURL+"/query?q="+url.QueryEscape(soql), nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.AccessToken)
+
+	// Make the API call
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check for successful response
+	if resp.StatusCode == http.StatusUnauthorized {
+		// 401 Unauthorized indicates session expired, try to refresh the token
+		// Get a new access token
+		_, err := s.GetAccessToken(ctx)
+		if err != nil {
+			return fmt.Errorf("error refreshing access token: %w", err)
+		}
+
+		// Retry the request with the new token
+		req.Header.Set("Authorization", "Bearer "+s.AccessToken)
+		resp, err = s.HTTPClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("error making request after token refresh: %w", err)
+		}
+		defer resp.Body.Close()
+
+		// Check the response again after retrying
+		if err := httpx.CheckResponse(resp); err != nil {
+			return fmt.Errorf("after token refresh: %w", err)
+		}
+	} else if err := httpx.CheckResponse(resp); err != nil {
+		return err
+	}
+
+	// Parse the JSON response into the provided destination
+	err = json.NewDecoder(resp.Body).Decode(dest)
+	if err != nil {
+		return fmt.Errorf("error parsing JSON response: %w", err)
+	}
+
+	return nil
+}
+
+// Contacts searches contacts by first or last name, email or account name.
+func (s *Client) Contacts(ctx context.Context, contactFilter string) (ContactList, error) {
+	// Escape quotes so the filter can't end the SOQL string literal
+	contactFilter = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(contactFilter)
+
+	soql := fmt.Sprintf("SELECT Id, FirstName, LastName, Email, Account.Name, Phone, Description FROM Contact "+
+		"WHERE LastName LIKE '%%%s%%' OR FirstName LIKE '%%%s%%'"+
+		"OR Account.Name LIKE '%%%s%%' OR Email LIKE '%%%s%%' ORDER BY LastName",
+		contactFilter, contactFilter, contactFilter, contactFilter)
+	var contactsResponse struct {
+		Records []Contact `json:"records"`
+	}
+	err := s.Query(ctx, soql, &contactsResponse)
+	return contactsResponse.Records, err
+}
+
+// ObjectCounts counts the accounts, contacts, opportunities and tasks in the deployment.
+func (s *Client) ObjectCounts(ctx context.Context) ObjectCounts {
+	// Define a list of SOQL queries for counting different objects
+	queries := map[string]string{
+		"accounts":      "SELECT COUNT() FROM Account",
+		"contacts":      "SELECT COUNT() FROM Contact",
+		"opportunities": "SELECT COUNT() FROM Opportunity",
+		"tasks":         "SELECT COUNT() FROM Task",
+	}
+
+	// Iterate through the queries in a stable order
+	objects := make([]string, 0, len(queries))
+	for object := range queries {
+		objects = append(objects, object)
+	}
+	sort.Strings(objects)
+
+	var counts ObjectCounts
+	for _, object := range objects {
+		var countResponse struct {
+			TotalSize int `json:"totalSize"`
+		}
+
+		// Execute the query and record errors
+		count := ObjectCount{Object: object}
+		err := s.Query(ctx, queries[object], &countResponse)
+		if err != nil {
+			count.Error = err.Error()
+		} else {
+			count.Count = countResponse.TotalSize
+		}
+
+		counts = append(counts, count)
+	}
+
+	return counts
+}
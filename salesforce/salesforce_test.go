@@ -0,0 +1,82 @@
+package salesforce
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestServer returns a Salesforce instance that issues a fresh access token and
+// rejects queries made with any other token, as it does once a session expires.
+func newTestServer(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/oauth2/token":
+			tokenRequests++
+			if r.Method != "POST" || r.FormValue("grant_type") != "client_credentials" || r.FormValue("client_id") != "key" {
+				t.Errorf("unexpected token request: %s %v", r.Method, r.Form)
+			}
+			http.ServeFile(w, r, filepath.Join("testdata", "token.json"))
+		case salesforceAPIBaseURL + "/query":
+			if r.Header.Get("Authorization") != "Bearer 00D5e000000Test!AQ0AQFreshToken" {
+				http.Error(w, `[{"message":"Session expired or invalid","errorCode":"INVALID_SESSION_ID"}]`, http.StatusUnauthorized)
+				return
+			}
+			if soql := r.URL.Query().Get("q"); !strings.Contains(soql, "FROM Contact") || !strings.Contains(soql, `'%O\'Brien%'`) {
+				t.Errorf("unexpected query: %s", soql)
+			}
+			http.ServeFile(w, r, filepath.Join("testdata", "contacts.json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &tokenRequests
+}
+
+func TestContactsRefreshesExpiredToken(t *testing.T) {
+	server, tokenRequests := newTestServer(t)
+
+	client := &Client{
+		Url:            server.URL,
+		ConsumerKey:    "key",
+		ConsumerSecret: "secret",
+		AccessToken:    "expired",
+		HTTPClient:     server.Client(),
+	}
+
+	contacts, err := client.Contacts(context.Background(), "O'Brien")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *tokenRequests != 1 {
+		t.Errorf("got %d token requests, want 1", *tokenRequests)
+	}
+
+	if len(contacts) != 2 {
+		t.Fatalf("got %d contacts, want 2", len(contacts))
+	}
+	if contacts[0].LastName != "Smith" || contacts[0].Account.Name != "Acme Corporation" {
+		t.Errorf("contact = %+v", contacts[0])
+	}
+	if contacts[1].Account.Name != "" || contacts[1].Phone != "" {
+		t.Errorf("contact with missing fields = %+v", contacts[1])
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("SALESFORCE_URL_1", "https://example.my.salesforce.com")
+	t.Setenv("SALESFORCE_CONSUMER_KEY_1", "key")
+	t.Setenv("SALESFORCE_CONSUMER_SECRET_1", "")
+
+	if _, err := FromEnv(); err == nil || !strings.Contains(err.Error(), "SALESFORCE_CONSUMER_SECRET_1") {
+		t.Errorf("error = %v, want missing SALESFORCE_CONSUMER_SECRET_1", err)
+	}
+}
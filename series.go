@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"polyapi/provider"
+	"polyapi/store"
+)
+
+// WatchedSeries is a BLS or FRED series on the watchlist.
+type WatchedSeries struct {
+	Source    string `json:"source"`
+	SeriesID  string `json:"series_id"`
+	Label     string `json:"label"`
+	Frequency string `json:"frequency,omitempty"`
+	Units     string `json:"units,omitempty"`
+	ReleaseID int    `json:"release_id,omitempty"`
+}
+
+// Watchlist is the list of series shown by the bls and fred commands.
+type Watchlist []WatchedSeries
+
+// PrintTable prints each series with its label, frequency and units.
+func (l Watchlist) PrintTable(w io.Writer) {
+	fmt.Fprintln(w)
+	for _, series := range l {
+		fmt.Fprintf(w, "  %-5s %-20s %s", series.Source, series.SeriesID, series.Label)
+		if series.Frequency != "" || series.Units != "" {
+			fmt.Fprintf(w, " (%s, %s)", series.Frequency, series.Units)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(l) == 0 {
+		fmt.Fprintln(w, "The watchlist is empty.")
+	}
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the watchlist columns.
+func (l Watchlist) CSVHeader() []string {
+	return []string{"source", "series_id", "label", "frequency", "units"}
+}
+
+// CSVRows returns one row per series.
+func (l Watchlist) CSVRows() [][]string {
+	var rows [][]string
+	for _, series := range l {
+		rows = append(rows, []string{series.Source, series.SeriesID, series.Label, series.Frequency, series.Units})
+	}
+	return rows
+}
+
+// seriesSources are the sources whose series can be put on the watchlist.
+var seriesSources = map[string]bool{"bls": true, "fred": true}
+
+// seriesCommand adds a series to the watchlist, removes one or lists them.
+func (a *app) seriesCommand(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return provider.Usagef("series requires a subcommand: add, remove or list")
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			return provider.Usagef("usage: series add bls|fred SERIES_ID [--label LABEL] [--frequency FREQUENCY] [--units UNITS] [--release ID]")
+		}
+		// Series IDs are upper case, as fred series looks them up
+		series := store.Series{Source: args[1], SeriesID: strings.ToUpper(args[2])}
+		if !seriesSources[series.Source] {
+			return provider.Usagef("unknown series source: %s (use bls or fred)", series.Source)
+		}
+
+		flags := flag.NewFlagSet("series add", flag.ContinueOnError)
+		flags.StringVar(&series.Label, "label", "", "name shown for the series")
+		flags.StringVar(&series.Frequency, "frequency", "", "how often the series is published, e.g. Monthly")
+		flags.StringVar(&series.Units, "units", "", "units of the series, e.g. Percent")
+		flags.IntVar(&series.ReleaseID, "release", 0, "FRED release that publishes the series, for the release calendar")
+		if err := provider.ParseFlags(ctx, flags, args[3:]); err != nil {
+			return err
+		}
+
+		if err := a.describeSeries(ctx, &series); err != nil {
+			return err
+		}
+		if err := a.store.AddSeries(series); err != nil {
+			return err
+		}
+		fmt.Printf("Added %s series %s: %s\n", series.Source, series.SeriesID, series.Label)
+		return nil
+	case "remove":
+		if len(args) != 3 {
+			return provider.Usagef("usage: series remove bls|fred SERIES_ID")
+		}
+		seriesID := strings.ToUpper(args[2])
+		if err := a.store.RemoveSeries(args[1], seriesID); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s series %s\n", args[1], seriesID)
+		return nil
+	case "list":
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+			if !seriesSources[source] {
+				return provider.Usagef("unknown series source: %s (use bls or fred)", source)
+			}
+		}
+
+		watchlist, err := a.store.Watchlist(source)
+		if err != nil {
+			return err
+		}
+		var result Watchlist
+		for _, series := range watchlist {
+			result = append(result, WatchedSeries{Source: series.Source, SeriesID: series.SeriesID, Label: series.Label,
+				Frequency: series.Frequency, Units: series.Units, ReleaseID: series.ReleaseID})
+		}
+		return render(result)
+	default:
+		return provider.Usagef("unknown series subcommand: %s", args[0])
+	}
+}
+
+// describeSeries fills in the label, frequency and units of a series that weren't given.
+// FRED series are looked up with the FRED API, which also checks the series exists.
+// BLS series are labelled with their catalog title when there's a BLS registration key,
+// or else their ID, and default to a monthly frequency.
+func (a *app) describeSeries(ctx context.Context, series *store.Series) error {
+
+	if series.Source == "bls" {
+		if series.Label == "" && a.bls.Client.APIKey != "" {
+			year := time.Now().Year()
+			fetched, err := a.bls.Client.Series(ctx, []string{series.SeriesID}, year-1, year)
+			if err != nil {
+				return fmt.Errorf("error looking up BLS series %s: %w", series.SeriesID, err)
+			}
+			if len(fetched) > 0 && fetched[0].Catalog != nil {
+				series.Label = fetched[0].Catalog.SeriesTitle
+			}
+		}
+		if series.Label == "" {
+			series.Label = series.SeriesID
+		}
+		if series.Frequency == "" {
+			series.Frequency = "Monthly"
+		}
+		return nil
+	}
+
+	if series.Label != "" && series.Frequency != "" && series.Units != "" {
+		return nil
+	}
+
+	info, err := a.fred.Client.SeriesInfo(ctx, series.SeriesID)
+	if err != nil {
+		return fmt.Errorf("error looking up FRED series %s: %w", series.SeriesID, err)
+	}
+	if series.Label == "" {
+		series.Label = info.Title
+	}
+	if series.Frequency == "" {
+		series.Frequency = info.Frequency
+	}
+	if series.Units == "" {
+		series.Units = info.Units
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"polyapi/provider"
+	"polyapi/store"
+)
+
+func TestSeriesIDsAreUpperCase(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	a := &app{store: s}
+	ctx := context.Background()
+
+	count := func() int {
+		t.Helper()
+		watchlist, err := s.Watchlist("fred")
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, series := range watchlist {
+			if series.SeriesID == "DGS10" {
+				n++
+			}
+		}
+		return n
+	}
+
+	for _, id := range []string{"dgs10", "DGS10"} {
+		if err := a.seriesCommand(ctx, []string{"add", "fred", id, "--label", "10-Year Treasury", "--frequency", "Daily", "--units", "Percent"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := count(); n != 1 {
+		t.Fatalf("DGS10 is on the watchlist %d times, want once", n)
+	}
+	if err := a.seriesCommand(ctx, []string{"remove", "fred", "dgs10"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 0 {
+		t.Errorf("DGS10 is still on the watchlist after removing dgs10")
+	}
+
+	var usage *provider.UsageError
+	if err := a.seriesCommand(ctx, []string{"add", "ecb", "X"}); !errors.As(err, &usage) {
+		t.Errorf("unknown source: %v, want a usage error", err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"polyapi/provider"
+	"polyapi/server"
+)
+
+// serveCommand serves the providers' results as JSON over HTTP, e.g.
+// `polyapi serve --addr :8080`, until it's interrupted. With --update the tracked
+// values published on /metrics are fetched periodically.
+func (a *app) serveCommand(ctx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", ":8080", "address to listen on, e.g. localhost:8080")
+	update := flags.Duration("update", 0, "fetch the tracked values every interval, e.g. 15m, so /metrics stays current")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Requests in progress are finished on Ctrl+C or when stopped by a service manager
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &server.Server{
+		Store:      a.store,
+		Weather:    a.weather,
+		Stocks:     a.stocks,
+		Treasury:   a.treasury,
+		BLS:        a.bls,
+		FRED:       a.fred,
+		ESPN:       a.espn,
+		Salesforce: a.salesforce,
+		Metrics:    a.metrics,
+	}
+
+	if *update > 0 {
+		go a.updateEvery(ctx, *update)
+	}
+
+	log.Printf("Serving on %s", *addr)
+	return srv.ListenAndServe(ctx, *addr)
+}
+
+// updateEvery updates the tracked values right away and then every interval until
+// ctx is done.
+func (a *app) updateEvery(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		a.updateValues(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// updateValues fetches the weather of each saved address, the quote of each saved
+// ticker symbol, the Treasury rates, the yield curve and the FRED and BLS watchlists, which saves their
+// latest values. Errors are logged and the other values are still updated.
+func (a *app) updateValues(ctx context.Context) {
+
+	addresses, err := a.store.Addresses()
+	if err != nil {
+		log.Printf("Error updating the weather: %v", err)
+	}
+	for _, address := range addresses {
+		if _, _, err := a.weather.Weather(ctx, address); err != nil {
+			log.Printf("Error updating the weather for %s: %v", address.MatchedAddress, err)
+		}
+	}
+
+	if provider.CheckConfig(a.stocks) == nil {
+		tickers, err := a.store.Tickers()
+		if err != nil {
+			log.Printf("Error updating the quotes: %v", err)
+		}
+		for _, ticker := range tickers {
+			if _, err := a.stocks.Quote(ctx, ticker.Ticker); err != nil {
+				log.Printf("Error updating the quote for %s: %v", ticker.Ticker, err)
+			}
+		}
+	}
+
+	if _, err := a.treasury.Rates(ctx); err != nil {
+		log.Printf("Error updating the Treasury rates: %v", err)
+	}
+	if _, err := a.treasury.YieldCurve(ctx); err != nil {
+		log.Printf("Error updating the yield curve: %v", err)
+	}
+	if _, err := a.bls.Latest(ctx); err != nil {
+		log.Printf("Error updating the BLS watchlist: %v", err)
+	}
+	if provider.CheckConfig(a.fred) == nil {
+		if _, err := a.fred.Dashboard(ctx); err != nil {
+			log.Printf("Error updating the FRED watchlist: %v", err)
+		}
+	}
+}
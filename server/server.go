@@ -0,0 +1,381 @@
+// Package server serves the providers' results as JSON over HTTP, e.g. GET /quote/AAPL,
+// so dashboards can use polyapi without running the CLI.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"polyapi/bls"
+	"polyapi/espn"
+	"polyapi/fred"
+	"polyapi/metrics"
+	"polyapi/output"
+	"polyapi/provider"
+	"polyapi/salesforce"
+	"polyapi/stocks"
+	"polyapi/store"
+	"polyapi/treasury"
+	"polyapi/weather"
+)
+
+// ShutdownTimeout is how long requests in progress have to finish once the server
+// is shutting down.
+const ShutdownTimeout = 30 * time.Second
+
+// Server serves the results of the providers, which save to the same store as the CLI.
+type Server struct {
+	Store      *store.Store
+	Weather    *weather.Provider
+	Stocks     *stocks.Provider
+	Treasury   *treasury.Provider
+	BLS        *bls.Provider
+	FRED       *fred.Provider
+	ESPN       *espn.Provider
+	Salesforce *salesforce.Provider
+	// Metrics counts the requests to the providers' APIs. Without it /metrics only
+	// has the stored values.
+	Metrics *metrics.API
+
+	shuttingDown atomic.Bool
+}
+
+// requestError is an invalid request, such as a missing parameter, which is
+// answered with 400 Bad Request.
+type requestError struct {
+	msg string
+}
+
+func (e *requestError) Error() string { return e.msg }
+
+// badRequest returns a requestError with a formatted message.
+func badRequest(format string, a ...any) error {
+	return &requestError{msg: fmt.Sprintf(format, a...)}
+}
+
+// endpoint gets a provider's result for a request.
+type endpoint func(r *http.Request) (output.Result, error)
+
+// treasuryParams are the query parameters of each Treasury dataset, which are passed
+// to the treasury command as flags.
+var treasuryParams = map[string][]string{
+	"curve":    nil,
+	"history":  {"from", "to"},
+	"debt":     {"from", "to", "sort", "limit", "filter"},
+	"exchange": {"from", "to", "sort", "limit", "filter", "country", "currency"},
+	"mts":      {"from", "to", "sort", "limit", "filter"},
+}
+
+// Handler returns the handler of every endpoint, which logs each request.
+func (s *Server) Handler() http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", s.health)
+	mux.HandleFunc("GET /readyz", s.ready)
+	mux.HandleFunc("GET /metrics", s.metrics)
+
+	mux.Handle("GET /weather", s.serve(s.Weather, func(r *http.Request) (output.Result, error) {
+		address := r.URL.Query().Get("address")
+		if address == "" {
+			return nil, badRequest("address is required, e.g. /weather?address=432+Park+Ave,+10022")
+		}
+		flags, err := queryFlags(r.URL.Query(), "address", "forecast", "hourly")
+		if err != nil {
+			return nil, err
+		}
+		return s.Weather.Fetch(r.Context(), flags)
+	}))
+
+	mux.Handle("GET /quote/{symbol}", s.serve(s.Stocks, func(r *http.Request) (output.Result, error) {
+		if _, err := queryFlags(r.URL.Query()); err != nil {
+			return nil, err
+		}
+		quote, err := s.Stocks.Quote(r.Context(), strings.ToUpper(r.PathValue("symbol")))
+		if err != nil {
+			return nil, err
+		}
+		return quote, nil
+	}))
+
+	mux.Handle("GET /treasury", s.serve(s.Treasury, func(r *http.Request) (output.Result, error) {
+		if _, err := queryFlags(r.URL.Query()); err != nil {
+			return nil, err
+		}
+		return s.Treasury.Fetch(r.Context(), nil)
+	}))
+
+	mux.Handle("GET /treasury/{dataset}", s.serve(s.Treasury, func(r *http.Request) (output.Result, error) {
+		dataset := r.PathValue("dataset")
+		params, ok := treasuryParams[dataset]
+		if !ok {
+			return nil, badRequest("unknown Treasury dataset: %s (use curve, history, debt, exchange or mts)", dataset)
+		}
+		args := []string{dataset}
+		query := r.URL.Query()
+		if dataset == "history" {
+			security := query.Get("security")
+			if security == "" {
+				return nil, badRequest("security is required, e.g. /treasury/history?security=Treasury+Bills")
+			}
+			args = append(args, security)
+			query.Del("security")
+		}
+		flags, err := queryFlags(query, params...)
+		if err != nil {
+			return nil, err
+		}
+		return s.Treasury.Fetch(r.Context(), append(args, flags...))
+	}))
+
+	mux.Handle("GET /bls", s.serve(s.BLS, func(r *http.Request) (output.Result, error) {
+		if _, err := queryFlags(r.URL.Query()); err != nil {
+			return nil, err
+		}
+		return s.BLS.Fetch(r.Context(), nil)
+	}))
+
+	mux.Handle("GET /fred", s.serve(s.FRED, func(r *http.Request) (output.Result, error) {
+		if _, err := queryFlags(r.URL.Query()); err != nil {
+			return nil, err
+		}
+		return s.FRED.Dashboard(r.Context())
+	}))
+
+	// Any FRED series can be summarized, not only those on the watchlist
+	mux.Handle("GET /fred/{series}", s.serve(s.FRED, func(r *http.Request) (output.Result, error) {
+		if _, err := queryFlags(r.URL.Query()); err != nil {
+			return nil, err
+		}
+		info, err := s.FRED.Client.SeriesInfo(r.Context(), strings.ToUpper(r.PathValue("series")))
+		if err != nil {
+			return nil, err
+		}
+		summary, err := s.FRED.Series(r.Context(), info)
+		if err != nil {
+			return nil, err
+		}
+		return fred.Report{Series: []fred.Summary{summary}}, nil
+	}))
+
+	mux.Handle("GET /espn/{league}", s.serve(s.ESPN, func(r *http.Request) (output.Result, error) {
+		league := r.PathValue("league")
+		if _, err := espn.ParseLeague(league); err != nil {
+			return nil, badRequest("%v", err)
+		}
+		flags, err := queryFlags(r.URL.Query(), "event")
+		if err != nil {
+			return nil, err
+		}
+		return s.ESPN.Fetch(r.Context(), append([]string{league}, flags...))
+	}))
+
+	mux.Handle("GET /salesforce/contacts", s.serve(s.Salesforce, func(r *http.Request) (output.Result, error) {
+		if _, err := queryFlags(r.URL.Query(), "q"); err != nil {
+			return nil, err
+		}
+		return s.Salesforce.Fetch(r.Context(), []string{"contacts", "--filter", r.URL.Query().Get("q")})
+	}))
+
+	mux.Handle("GET /salesforce/counts", s.serve(s.Salesforce, func(r *http.Request) (output.Result, error) {
+		if _, err := queryFlags(r.URL.Query()); err != nil {
+			return nil, err
+		}
+		return s.Salesforce.Fetch(r.Context(), []string{"counts"})
+	}))
+
+	return logRequests(mux)
+}
+
+// serve returns a handler that writes the result of an endpoint as JSON, or as CSV
+// with ?format=csv. A provider that isn't configured answers 503 Service Unavailable
+// and one that fails 502 Bad Gateway, with the error as JSON.
+func (s *Server) serve(p provider.Provider, fn endpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+		if format != "json" && format != "csv" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unknown format: %s (use json or csv)", format))
+			return
+		}
+
+		if err := provider.CheckConfig(p); err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+
+		// Invalid parameters are answered with the error, not printed with the usage
+		result, err := fn(r.WithContext(provider.WithFlagOutput(r.Context(), io.Discard)))
+		if err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		if err := output.Write(w, format, result); err != nil {
+			log.Printf("Error writing %s: %v", r.URL.Path, err)
+		}
+	})
+}
+
+// errorStatus returns the status code for an error: 400 for an invalid request, such
+// as a parameter the provider's flags reject, 504 if the provider's API timed out, or
+// else 502.
+func errorStatus(err error) int {
+
+	var reqErr *requestError
+	var usageErr *provider.UsageError
+	switch {
+	case errors.As(err, &reqErr), errors.As(err, &usageErr):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// writeError writes an error as JSON, e.g. {"error":"unknown league: xfl"}.
+func writeError(w http.ResponseWriter, status int, err error) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// queryFlags returns the query parameters as flags of a provider command, e.g.
+// --from=2024-01-01 for from=2024-01-01, rejecting parameters that aren't allowed.
+// format is allowed everywhere and isn't passed on.
+func queryFlags(query url.Values, allowed ...string) ([]string, error) {
+
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var flags []string
+	for _, name := range names {
+		if name == "format" {
+			continue
+		}
+		if !slices.Contains(allowed, name) {
+			return nil, badRequest("unknown parameter: %s", name)
+		}
+		for _, value := range query[name] {
+			flags = append(flags, "--"+name+"="+value)
+		}
+	}
+
+	return flags, nil
+}
+
+// health answers that the server is running.
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// ready answers whether the server can take requests: the database can be reached
+// and the server isn't shutting down.
+func (s *Server) ready(w http.ResponseWriter, r *http.Request) {
+
+	if s.shuttingDown.Load() {
+		writeError(w, http.StatusServiceUnavailable, errors.New("shutting down"))
+		return
+	}
+	if err := s.Store.DB.PingContext(r.Context()); err != nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("database unavailable: %w", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
+}
+
+// metrics writes the latest stored values and the API metrics in the Prometheus
+// text format.
+func (s *Server) metrics(w http.ResponseWriter, r *http.Request) {
+
+	families, err := metrics.Values(s.Store)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if s.Metrics != nil {
+		families = append(families, s.Metrics.Families()...)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := metrics.Write(w, families); err != nil {
+		log.Printf("Error writing %s: %v", r.URL.Path, err)
+	}
+}
+
+// ListenAndServe serves on addr until ctx is done, then stops taking new requests and
+// waits up to ShutdownTimeout for those in progress.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Print("Shutting down")
+	s.shuttingDown.Store(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
+
+// statusRecorder records the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs the method, path, status and duration of each request. The query
+// isn't logged, since it can hold a street address or a contact search.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.EscapedPath(), rec.status, time.Since(start).Round(time.Millisecond))
+	})
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"polyapi/espn"
+	"polyapi/metrics"
+	"polyapi/salesforce"
+	"polyapi/stocks"
+	"polyapi/store"
+	"polyapi/treasury"
+)
+
+// newTestServer returns a server whose ESPN API serves the NFL scoreboard fixture and
+// whose Treasury API answers 404 Not Found.
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/nfl/scoreboard") {
+			http.ServeFile(w, r, filepath.Join("..", "espn", "testdata", "nfl_scoreboard.json"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(api.Close)
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	// ESPN's requests are counted in the metrics
+	apiMetrics := &metrics.API{}
+	espnClient := espn.NewClient()
+	espnClient.HTTPClient = &http.Client{Transport: apiMetrics.Transport("espn", api.Client().Transport, nil)}
+	espnClient.BaseURL = api.URL
+	treasuryClient := treasury.NewClient()
+	treasuryClient.HTTPClient = api.Client()
+	treasuryClient.BaseURL = api.URL
+
+	return &Server{
+		Store:    s,
+		Stocks:   stocks.NewProvider(stocks.NewClient(""), s),
+		Treasury: treasury.NewProvider(treasuryClient, s),
+		ESPN:     espn.NewProvider(espnClient),
+		Metrics:  apiMetrics,
+	}
+}
+
+// get requests a path from the server and returns the response.
+func get(t *testing.T, handler http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestSchedule(t *testing.T) {
+	handler := newTestServer(t).Handler()
+
+	rec := get(t, handler, "/espn/nfl")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var schedule espn.Schedule
+	if err := json.Unmarshal(rec.Body.Bytes(), &schedule); err != nil {
+		t.Fatal(err)
+	}
+	if schedule.League != "NFL" || len(schedule.Games) != 2 {
+		t.Errorf("schedule = %+v", schedule)
+	}
+
+	rec = get(t, handler, "/espn/nfl?event=1&format=csv")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Errorf("status = %d, content type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "BAL @ KC") {
+		t.Errorf("body = %s, want the first game", rec.Body)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Setenv("ALPHAVANTAGE_API_KEY", "")
+	handler := newTestServer(t).Handler()
+
+	tests := []struct {
+		path   string
+		status int
+		error  string
+	}{
+		{"/espn/xfl", http.StatusBadRequest, "unknown league: xfl"},
+		{"/espn/nfl?week=1", http.StatusBadRequest, "unknown parameter: week"},
+		{"/espn/nfl?format=xml", http.StatusBadRequest, "unknown format: xml"},
+		{"/weather", http.StatusBadRequest, "address is required"},
+		{"/treasury/bonds", http.StatusBadRequest, "unknown Treasury dataset: bonds"},
+		{"/weather?address=x&forecast", http.StatusBadRequest, "invalid boolean value"},
+		{"/treasury/debt?limit=abc", http.StatusBadRequest, "invalid value"},
+		{"/treasury/history?security=Treasury+Bills&from=bad", http.StatusBadRequest, `invalid date "bad"`},
+		{"/espn/nfl?event=9", http.StatusBadRequest, "event number out of range: 9"},
+		{"/quote/AAPL", http.StatusServiceUnavailable, "ALPHAVANTAGE_API_KEY"},
+		{"/treasury", http.StatusBadGateway, "404 Not Found"},
+	}
+
+	for _, tt := range tests {
+		rec := get(t, handler, tt.path)
+		var body struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Errorf("%s: %v in %s", tt.path, err, rec.Body)
+			continue
+		}
+		if rec.Code != tt.status || !strings.Contains(body.Error, tt.error) {
+			t.Errorf("%s: status = %d, error = %q, want %d and %q", tt.path, rec.Code, body.Error, tt.status, tt.error)
+		}
+	}
+
+	if rec := get(t, handler, "/nba"); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d for an unknown path, want 404", rec.Code)
+	}
+}
+
+func TestLogOmitsQuery(t *testing.T) {
+	for _, name := range salesforce.RequiredVars {
+		t.Setenv(name, "")
+	}
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	get(t, newTestServer(t).Handler(), "/salesforce/contacts?q=Jane+Smith")
+	if !strings.Contains(logs.String(), "GET /salesforce/contacts 503") || strings.Contains(logs.String(), "Smith") {
+		t.Errorf("log = %q, want the path without the search", logs.String())
+	}
+}
+
+func TestHealthAndReadiness(t *testing.T) {
+	srv := newTestServer(t)
+	handler := srv.Handler()
+
+	if rec := get(t, handler, "/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("health status = %d", rec.Code)
+	}
+	if rec := get(t, handler, "/readyz"); rec.Code != http.StatusOK {
+		t.Errorf("readiness status = %d: %s", rec.Code, rec.Body)
+	}
+
+	srv.shuttingDown.Store(true)
+	if rec := get(t, handler, "/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("readiness status = %d while shutting down, want 503", rec.Code)
+	}
+	if rec := get(t, handler, "/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("health status = %d while shutting down", rec.Code)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	handler := newTestServer(t).Handler()
+
+	get(t, handler, "/espn/nfl")
+	rec := get(t, handler, "/metrics")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("status = %d, content type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, line := range []string{
+		"# TYPE polyapi_temperature_fahrenheit gauge",
+		`polyapi_api_requests_total{provider="espn"} 1`,
+		"polyapi_alphavantage_quota_hits_total 0",
+	} {
+		if !strings.Contains(rec.Body.String(), line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package stocks
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"time"
+
+	"polyapi/output"
+	"polyapi/provider"
+	"polyapi/store"
+)
+
+// Provider quotes ticker symbols and saves them with their last price.
+type Provider struct {
+	Client *Client
+	Store  *store.Store
+}
+
+// NewProvider returns a stock quote provider that saves ticker symbols to s.
+func NewProvider(client *Client, s *store.Store) *Provider {
+	return &Provider{Client: client, Store: s}
+}
+
+func (p *Provider) Name() string { return "quote" }
+
+func (p *Provider) Description() string {
+	return "Alpha Vantage stock quotes and company overviews (quote SYMBOL...)"
+}
+
+func (p *Provider) RequiredConfig() []string { return []string{"ALPHAVANTAGE_API_KEY"} }
+
+// Fetch quotes each ticker symbol given as an argument.
+func (p *Provider) Fetch(ctx context.Context, args []string) (output.Result, error) {
+
+	symbols, err := provider.ParseInterspersed(ctx, flag.NewFlagSet("quote", flag.ContinueOnError), args)
+	if err != nil {
+		return nil, err
+	}
+	if len(symbols) == 0 {
+		return nil, provider.Usagef("quote requires a ticker symbol, e.g. polyapi quote AAPL")
+	}
+
+	var quotes Quotes
+	for _, symbol := range symbols {
+		quote, err := p.Quote(ctx, strings.ToUpper(strings.TrimSpace(symbol)))
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, nil
+}
+
+// Quote gets a stock quote with the company overview, saves the ticker symbol
+// with its last price to the database and adds the quote to its price history.
+func (p *Provider) Quote(ctx context.Context, tickerSymbol string) (Quote, error) {
+
+	quote, err := p.Price(ctx, tickerSymbol)
+	if err != nil {
+		return quote, err
+	}
+
+	overview, err := p.Client.Overview(ctx, quote.Symbol)
+	if err != nil {
+		return quote, err
+	}
+	quote.Overview = &overview
+
+	company := store.Company{
+		Name:                 overview.Name,
+		Sector:               overview.Sector,
+		Industry:             overview.Industry,
+		Exchange:             overview.Exchange,
+		Address:              overview.Address,
+		OfficialSite:         overview.OfficialSite,
+		RevenueTTM:           overview.RevenueTTM,
+		MarketCapitalization: overview.MarketCapitalization,
+		FiscalYearEnd:        overview.FiscalYearEnd,
+	}
+	if err := p.Store.SaveTicker(quote.Symbol, company, quote.Price); err != nil {
+		return quote, err
+	}
+
+	return quote, nil
+}
+
+// Price gets a stock quote without the company overview, which saves an Alpha Vantage
+// request, and adds the quote to its price history.
+func (p *Provider) Price(ctx context.Context, tickerSymbol string) (Quote, error) {
+
+	quote, err := p.Client.Quote(ctx, tickerSymbol)
+	if err != nil {
+		return quote, err
+	}
+
+	err = p.Store.AddPriceQuote(store.PriceQuote{
+		Ticker:        quote.Symbol,
+		Price:         quote.Price,
+		Open:          quote.Open,
+		High:          quote.High,
+		Low:           quote.Low,
+		PreviousClose: quote.PreviousClose,
+		Change:        quote.Change,
+		ChangePercent: quote.ChangePercent,
+		Volume:        quote.Volume,
+		TradingDay:    quote.TradingDay,
+		Source:        "alphavantage",
+		RecordedAt:    time.Now(),
+	})
+	if err != nil {
+		return quote, err
+	}
+
+	return quote, nil
+}
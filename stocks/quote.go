@@ -0,0 +1,134 @@
+package stocks
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"polyapi/output"
+)
+
+// Overview is the Alpha Vantage overview of a company.
+// Values are kept as returned by the API, which uses "None" for missing data.
+type Overview struct {
+	Name                 string `json:"name"`
+	Exchange             string `json:"exchange"`
+	Sector               string `json:"sector"`
+	Industry             string `json:"industry"`
+	FiscalYearEnd        string `json:"fiscal_year_end"`
+	LatestQuarter        string `json:"latest_quarter"`
+	Address              string `json:"address"`
+	OfficialSite         string `json:"official_site"`
+	MarketCapitalization string `json:"market_capitalization"`
+	RevenueTTM           string `json:"revenue_ttm"`
+	DividendDate         string `json:"dividend_date"`
+	WeekHigh52           string `json:"52_week_high"`
+	WeekLow52            string `json:"52_week_low"`
+	AnalystTargetPrice   string `json:"analyst_target_price"`
+	PERatio              string `json:"pe_ratio"`
+	Beta                 string `json:"beta"`
+	ForwardPE            string `json:"forward_pe"`
+	TrailingPE           string `json:"trailing_pe"`
+}
+
+// Quote is the Alpha Vantage global quote for a ticker symbol with its company overview.
+type Quote struct {
+	Symbol        string    `json:"symbol"`
+	Price         float64   `json:"price"`
+	Open          float64   `json:"open"`
+	High          float64   `json:"high"`
+	Low           float64   `json:"low"`
+	PreviousClose float64   `json:"previous_close"`
+	Change        float64   `json:"change"`
+	ChangePercent float64   `json:"change_percent"`
+	Volume        int64     `json:"volume"`
+	TradingDay    string    `json:"trading_day"`
+	Overview      *Overview `json:"overview,omitempty"`
+}
+
+// Quotes is a list of quotes rendered together, e.g. `polyapi quote AAPL MSFT`.
+type Quotes []Quote
+
+// PrintTable prints the quote followed by the company overview.
+func (q Quote) PrintTable(w io.Writer) {
+
+	fmt.Fprintf(w, "Symbol: %s Price: %.2f Open: %.2f Change: %.2f Change Percent: %.4f%%\n", q.Symbol, q.Price, q.Open, q.Change, q.ChangePercent)
+	fmt.Fprintf(w, "   High: %.2f Low: %.2f Previous Close: %.2f\n", q.High, q.Low, q.PreviousClose)
+
+	if q.Overview == nil {
+		fmt.Fprintln(w)
+		return
+	}
+	data := q.Overview
+
+	fmt.Fprintf(w, "\n   Exchange: %s\n", data.Exchange)
+	fmt.Fprintf(w, "   Sector: %s\n", data.Sector)
+	fmt.Fprintf(w, "   Industry: %s\n", data.Industry)
+	fmt.Fprintf(w, "   Fiscal Year End: %s\n", data.FiscalYearEnd)
+	fmt.Fprintf(w, "   Latest Quarter: %s\n", data.LatestQuarter)
+
+	fmt.Fprintf(w, "\n   Address: %s\n", data.Address)
+
+	fmt.Fprintf(w, "\n   Official Website: %s\n", data.OfficialSite)
+
+	fmt.Fprintf(w, "\n   Market Cap (B): %s\n", formatBillions(data.MarketCapitalization))
+	fmt.Fprintf(w, "   Revenue TTM (B): %s\n", formatBillions(data.RevenueTTM))
+	fmt.Fprintf(w, "   Dividend Date: %s\n", data.DividendDate)
+
+	fmt.Fprintf(w, "\n   52 Week High: %s\n", data.WeekHigh52)
+	fmt.Fprintf(w, "   52 Week Low: %s\n", data.WeekLow52)
+	fmt.Fprintf(w, "   Analyst Target Price: %s\n", data.AnalystTargetPrice)
+
+	fmt.Fprintf(w, "\n   PE Ratio: %s\n", data.PERatio)
+	fmt.Fprintf(w, "   Beta: %s\n", data.Beta)
+	fmt.Fprintf(w, "   Forward PE: %s\n", data.ForwardPE)
+	fmt.Fprintf(w, "   Trailing PE: %s\n", data.TrailingPE)
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the quote and overview columns.
+func (q Quote) CSVHeader() []string {
+	return []string{"symbol", "price", "open", "high", "low", "previous_close", "change", "change_percent",
+		"volume", "trading_day", "name", "exchange", "sector", "industry", "market_capitalization", "revenue_ttm", "pe_ratio", "beta"}
+}
+
+// CSVRows returns the quote as a single row.
+func (q Quote) CSVRows() [][]string {
+	row := []string{q.Symbol, output.FormatFloat(q.Price), output.FormatFloat(q.Open), output.FormatFloat(q.High), output.FormatFloat(q.Low),
+		output.FormatFloat(q.PreviousClose), output.FormatFloat(q.Change), output.FormatFloat(q.ChangePercent),
+		strconv.FormatInt(q.Volume, 10), q.TradingDay}
+	if q.Overview != nil {
+		row = append(row, q.Overview.Name, q.Overview.Exchange, q.Overview.Sector, q.Overview.Industry,
+			q.Overview.MarketCapitalization, q.Overview.RevenueTTM, q.Overview.PERatio, q.Overview.Beta)
+	} else {
+		row = append(row, "", "", "", "", "", "", "", "")
+	}
+	return [][]string{row}
+}
+
+// PrintTable prints each quote in turn.
+func (q Quotes) PrintTable(w io.Writer) {
+	for _, quote := range q {
+		quote.PrintTable(w)
+	}
+}
+
+// CSVHeader returns the quote and overview columns.
+func (q Quotes) CSVHeader() []string {
+	return Quote{}.CSVHeader()
+}
+
+// CSVRows returns one row per quote.
+func (q Quotes) CSVRows() [][]string {
+	var rows [][]string
+	for _, quote := range q {
+		rows = append(rows, quote.CSVRows()...)
+	}
+	return rows
+}
+
+// formatBillions formats a dollar amount returned by the API in billions, e.g. "3.45B".
+func formatBillions(amount string) string {
+	amountFloat, _ := strconv.ParseFloat(amount, 64)
+	return fmt.Sprintf("%.2fB", amountFloat/1e9)
+}
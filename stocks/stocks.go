@@ -0,0 +1,173 @@
+// Package stocks gets stock quotes and company overviews from the Alpha Vantage API.
+package stocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"polyapi/httpx"
+)
+
+// ErrQuotaExceeded is returned when the Alpha Vantage daily API quota has been used up.
+var ErrQuotaExceeded = errors.New("daily API quota exceeded. Please refer to Alpha Vantage's premium plans for higher limits")
+
+// Client calls the Alpha Vantage API.
+// Get a free API key at https://www.alphavantage.co/support/#api-key
+type Client struct {
+	APIKey     string
+	HTTPClient *http.Client
+	BaseURL    string
+	// QuotaExceeded, if set, is called each time a request is refused because the
+	// daily quota is used up, e.g. to count it in the metrics.
+	QuotaExceeded func()
+}
+
+// DefaultBaseURL is the base URL of the Alpha Vantage API.
+const DefaultBaseURL = "https://www.alphavantage.co"
+
+// NewClient returns an Alpha Vantage client using apiKey.
+func NewClient(apiKey string) *Client {
+	return &Client{APIKey: apiKey, HTTPClient: httpx.DefaultClient, BaseURL: DefaultBaseURL}
+}
+
+// CheckBody returns an error for an Alpha Vantage response body that's an error sent
+// with 200 OK: ErrQuotaExceeded for the daily quota's "Information" message, or the
+// "Error Message" of an invalid request. Bodies that aren't JSON objects are left to
+// the decoder.
+func CheckBody(body []byte) error {
+
+	var data struct {
+		Information  string `json:"Information"`
+		ErrorMessage string `json:"Error Message"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil
+	}
+
+	switch {
+	case data.Information != "":
+		return ErrQuotaExceeded
+	case data.ErrorMessage != "":
+		return fmt.Errorf("Alpha Vantage error: %s", data.ErrorMessage)
+	}
+	return nil
+}
+
+// quotaExceeded reports a request refused for the quota and returns ErrQuotaExceeded.
+func (c *Client) quotaExceeded() error {
+	if c.QuotaExceeded != nil {
+		c.QuotaExceeded()
+	}
+	return ErrQuotaExceeded
+}
+
+// query calls an Alpha Vantage function for a ticker symbol and decodes the JSON response into dest.
+func (c *Client) query(ctx context.Context, function, tickerSymbol string, dest interface{}) error {
+
+	if c.APIKey == "" {
+		return fmt.Errorf("ALPHAVANTAGE_API_KEY environment variable is not set")
+	}
+
+	url := fmt.Sprintf("%s/query?function=%s&symbol=%s&apikey=%s", c.BaseURL, function, url.QueryEscape(tickerSymbol), c.APIKey)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if err := httpx.CheckResponse(resp); err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+// Overview sends a request to the Alpha Vantage API to get an overview of a company
+func (c *Client) Overview(ctx context.Context, tickerSymbol string) (Overview, error) {
+
+	var data map[string]string
+	if err := c.query(ctx, "OVERVIEW", tickerSymbol, &data); err != nil {
+		return Overview{}, err
+	}
+
+	// Check for quota exceeded message
+	if _, ok := data["Information"]; ok {
+		return Overview{}, c.quotaExceeded()
+	}
+
+	overview := Overview{
+		Name:                 data["Name"],
+		Exchange:             data["Exchange"],
+		Sector:               data["Sector"],
+		Industry:             data["Industry"],
+		FiscalYearEnd:        data["FiscalYearEnd"],
+		LatestQuarter:        data["LatestQuarter"],
+		Address:              data["Address"],
+		OfficialSite:         data["OfficialSite"],
+		MarketCapitalization: data["MarketCapitalization"],
+		RevenueTTM:           data["RevenueTTM"],
+		DividendDate:         data["DividendDate"],
+		WeekHigh52:           data["52WeekHigh"],
+		WeekLow52:            data["52WeekLow"],
+		AnalystTargetPrice:   data["AnalystTargetPrice"],
+		PERatio:              data["PERatio"],
+		Beta:                 data["Beta"],
+		ForwardPE:            data["ForwardPE"],
+		TrailingPE:           data["TrailingPE"],
+	}
+
+	return overview, nil
+}
+
+// Quote sends a request to the Alpha Vantage API to get a stock quote for a ticker symbol.
+func (c *Client) Quote(ctx context.Context, tickerSymbol string) (Quote, error) {
+
+	var data struct {
+		GlobalQuote map[string]string `json:"Global Quote"`
+		Information string            `json:"Information"`
+	}
+
+	if err := c.query(ctx, "GLOBAL_QUOTE", tickerSymbol, &data); err != nil {
+		return Quote{}, err
+	}
+
+	// Check for quota exceeded message
+	if data.Information != "" {
+		return Quote{}, c.quotaExceeded()
+	}
+
+	if data.GlobalQuote["01. symbol"] == "" {
+		return Quote{}, fmt.Errorf("invalid ticker symbol: %s", tickerSymbol)
+	}
+
+	quote := Quote{
+		Symbol:        data.GlobalQuote["01. symbol"],
+		Price:         parseFloat(data.GlobalQuote["05. price"]),
+		Open:          parseFloat(data.GlobalQuote["02. open"]),
+		High:          parseFloat(data.GlobalQuote["03. high"]),
+		Low:           parseFloat(data.GlobalQuote["04. low"]),
+		PreviousClose: parseFloat(data.GlobalQuote["08. previous close"]),
+		Change:        parseFloat(data.GlobalQuote["09. change"]),
+		ChangePercent: parseFloat(strings.TrimSuffix(data.GlobalQuote["10. change percent"], "%")),
+		TradingDay:    data.GlobalQuote["07. latest trading day"],
+	}
+	quote.Volume, _ = strconv.ParseInt(data.GlobalQuote["06. volume"], 10, 64)
+
+	return quote, nil
+}
+
+// parseFloat parses a number returned as a string by the API, returning 0 if it isn't a number.
+func parseFloat(value string) float64 {
+	number, _ := strconv.ParseFloat(value, 64)
+	return number
+}
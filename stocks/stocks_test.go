@@ -0,0 +1,135 @@
+package stocks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"polyapi/httpx"
+)
+
+// newTestClient returns a client for a server that serves the fixture in testdata
+// for each Alpha Vantage function in fixtures.
+func newTestClient(t *testing.T, fixtures map[string]string) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/query" || r.URL.Query().Get("apikey") != "test-key" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		fixture, ok := fixtures[r.URL.Query().Get("function")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", fixture))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test-key")
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	return client
+}
+
+func TestQuote(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"GLOBAL_QUOTE": "global_quote.json",
+		"OVERVIEW":     "overview.json",
+	})
+
+	quote, err := client.Quote(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Quote{
+		Symbol:        "AAPL",
+		Price:         227.18,
+		Open:          226.76,
+		High:          227.28,
+		Low:           223.89,
+		PreviousClose: 226.84,
+		Change:        0.34,
+		ChangePercent: 0.1499,
+		Volume:        30602208,
+		TradingDay:    "2024-08-26",
+	}
+	if quote != want {
+		t.Errorf("Quote = %+v, want %+v", quote, want)
+	}
+
+	overview, err := client.Overview(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overview.Name != "Apple Inc" || overview.WeekHigh52 != "237.23" {
+		t.Errorf("Overview = %+v", overview)
+	}
+	if got := formatBillions(overview.RevenueTTM); got != "385.60B" {
+		t.Errorf("formatBillions = %s", got)
+	}
+}
+
+func TestQuoteQuotaExceeded(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"GLOBAL_QUOTE": "quota.json",
+	})
+	var hits int
+	client.QuotaExceeded = func() { hits++ }
+
+	_, err := client.Quote(context.Background(), "AAPL")
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("error = %v, want ErrQuotaExceeded", err)
+	}
+	if hits != 1 {
+		t.Errorf("%d quota hits reported, want 1", hits)
+	}
+}
+
+// mapCache is an httpx.Cache kept in a map.
+type mapCache map[string]httpx.CacheEntry
+
+func (c mapCache) Get(key string) (httpx.CacheEntry, bool, error) {
+	entry, ok := c[key]
+	return entry, ok, nil
+}
+
+func (c mapCache) Put(entry httpx.CacheEntry) error {
+	c[entry.Key] = entry
+	return nil
+}
+
+func TestQuotaIsNotCached(t *testing.T) {
+	fixture := "quota.json"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join("testdata", fixture))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key")
+	client.BaseURL = server.URL
+	client.HTTPClient = &http.Client{Transport: &httpx.CacheTransport{
+		Base: server.Client().Transport, Cache: mapCache{}, TTL: time.Hour, Validate: CheckBody,
+	}}
+	var hits int
+	client.QuotaExceeded = func() { hits++ }
+
+	if _, err := client.Quote(context.Background(), "AAPL"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("error = %v, want ErrQuotaExceeded", err)
+	}
+
+	// Once the quota resets the quote is fetched rather than the cached quota message
+	fixture = "global_quote.json"
+	quote, err := client.Quote(context.Background(), "AAPL")
+	if err != nil || quote.Price != 227.18 {
+		t.Errorf("Quote = %+v, %v after the quota reset", quote, err)
+	}
+	if hits != 1 {
+		t.Errorf("%d quota hits, want 1", hits)
+	}
+}
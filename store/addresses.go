@@ -0,0 +1,145 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Address is a geocoded address saved for re-use.
+// Its region is empty until it's looked up.
+type Address struct {
+	Id              int
+	MatchedAddress  string
+	Latitude        float64
+	Longitude       float64
+	LastTemperature string
+	UpdatedAt       string
+	Region          Region
+}
+
+// Region is the state and metropolitan statistical area of an address. StateFIPS is
+// the 2-digit state code and State its abbreviation, and CBSA is the 5-digit code of
+// the metro area, which is empty outside of one.
+type Region struct {
+	StateFIPS string
+	State     string
+	CBSA      string
+	Metro     string
+}
+
+// Addresses returns the unique saved addresses.
+func (s *Store) Addresses() ([]Address, error) {
+
+	// Retrieve unique addresses from the database
+	rows, err := s.DB.Query(`
+		SELECT id, address, lat, lon, updated_at, last_temperature, state_fips, state, cbsa, metro
+		FROM addresses GROUP BY address
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addresses []Address
+	for rows.Next() {
+		var address Address
+		var updatedAt, lastTemperature interface{}
+		err := rows.Scan(&address.Id, &address.MatchedAddress, &address.Latitude, &address.Longitude, &updatedAt, &lastTemperature,
+			&address.Region.StateFIPS, &address.Region.State, &address.Region.CBSA, &address.Region.Metro)
+		if err != nil {
+			return nil, err
+		}
+
+		if t, ok := updatedAt.(time.Time); ok {
+			address.UpdatedAt = t.Format("2006-01-02T15:04:05")
+		}
+
+		if lastTemperature != nil {
+			address.LastTemperature = fmt.Sprintf("%v", lastTemperature)
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	return addresses, rows.Err()
+}
+
+// SaveAddress saves a geocoded address, reusing the existing record if it was saved before.
+func (s *Store) SaveAddress(matchedAddress string, lat, lon float64) (Address, error) {
+
+	address := Address{
+		MatchedAddress: matchedAddress,
+		Latitude:       lat,
+		Longitude:      lon,
+	}
+
+	// Reuse the saved address if it was looked up before
+	err := s.DB.QueryRow("SELECT id, state_fips, state, cbsa, metro FROM addresses WHERE address = ? ORDER BY id LIMIT 1", matchedAddress).
+		Scan(&address.Id, &address.Region.StateFIPS, &address.Region.State, &address.Region.CBSA, &address.Region.Metro)
+	if err == nil {
+		return address, nil
+	}
+	if err != sql.ErrNoRows {
+		return address, err
+	}
+
+	// Insert new address into database
+	result, err := s.DB.Exec("INSERT INTO addresses (address, lat, lon) VALUES (?, ?, ?)", matchedAddress, lat, lon)
+	if err != nil {
+		return address, err
+	}
+
+	// Get the ID of the inserted record
+	insertedId, err := result.LastInsertId()
+	if err != nil {
+		return address, fmt.Errorf("error getting inserted ID: %w", err)
+	}
+	address.Id = int(insertedId)
+
+	return address, nil
+}
+
+// UpdateTemperature records the latest temperature for an address.
+func (s *Store) UpdateTemperature(addressId int, temperature string) error {
+	_, err := s.DB.Exec("UPDATE addresses SET last_temperature = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", temperature, addressId)
+	if err != nil {
+		return fmt.Errorf("error updating address record: %w", err)
+	}
+	return nil
+}
+
+// UpdateRegion records the state and metro area of an address.
+func (s *Store) UpdateRegion(addressId int, region Region) error {
+	_, err := s.DB.Exec("UPDATE addresses SET state_fips = ?, state = ?, cbsa = ?, metro = ? WHERE id = ?",
+		region.StateFIPS, region.State, region.CBSA, region.Metro, addressId)
+	if err != nil {
+		return fmt.Errorf("error updating address region: %w", err)
+	}
+	return nil
+}
+
+// DeleteAddress deletes an address and its temperature history from the database.
+// It reports whether the address was found.
+func (s *Store) DeleteAddress(id int) (bool, error) {
+
+	var rowsAffected int64
+	err := s.inTx(func(tx *sql.Tx) error {
+
+		// Delete the history first since address IDs can be reused
+		if _, err := tx.Exec("DELETE FROM temperature_readings WHERE address_id = ?", id); err != nil {
+			return err
+		}
+
+		// Delete the address from the database
+		result, err := tx.Exec("DELETE FROM addresses WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err = result.RowsAffected()
+		return err
+	})
+
+	return rowsAffected > 0, err
+}
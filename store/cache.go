@@ -0,0 +1,84 @@
+package store
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"polyapi/httpx"
+)
+
+// CacheMaxAge is how long a response is kept after it was last fetched, to be served
+// stale when its API can't be reached. Older responses are deleted.
+const CacheMaxAge = 30 * 24 * time.Hour
+
+// pruneInterval is how often Put deletes the responses older than CacheMaxAge.
+const pruneInterval = time.Hour
+
+// HTTPCache is the http_cache table, used by httpx.CacheTransport to keep API
+// responses between runs.
+type HTTPCache struct {
+	store *Store
+
+	mu       sync.Mutex
+	prunedAt time.Time
+}
+
+// HTTPCache returns the response cache kept in the database.
+func (s *Store) HTTPCache() *HTTPCache {
+	return &HTTPCache{store: s}
+}
+
+// Get returns the cached response for key.
+func (c *HTTPCache) Get(key string) (httpx.CacheEntry, bool, error) {
+
+	entry := httpx.CacheEntry{Key: key}
+	var fetchedAt int64
+
+	err := c.store.DB.QueryRow(`
+		SELECT url, content_type, body, etag, last_modified, fetched_at
+		FROM http_cache WHERE key = ?
+	`, key).Scan(&entry.URL, &entry.ContentType, &entry.Body, &entry.ETag, &entry.LastModified, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, err
+	}
+
+	entry.FetchedAt = time.Unix(fetchedAt, 0)
+	return entry, true, nil
+}
+
+// Put saves a response, replacing the cached response with the same key. The first
+// Put, and then one an hour, deletes the responses older than CacheMaxAge, so every
+// address, ticker or series ever looked up doesn't stay in the database.
+func (c *HTTPCache) Put(entry httpx.CacheEntry) error {
+
+	if err := c.prune(time.Now()); err != nil {
+		return err
+	}
+
+	_, err := c.store.DB.Exec(`
+		INSERT OR REPLACE INTO http_cache (key, url, content_type, body, etag, last_modified, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, entry.Key, entry.URL, entry.ContentType, entry.Body, entry.ETag, entry.LastModified, entry.FetchedAt.Unix())
+	return err
+}
+
+// prune deletes the responses fetched more than CacheMaxAge before now, unless it
+// did so less than pruneInterval ago.
+func (c *HTTPCache) prune(now time.Time) error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.prunedAt) < pruneInterval {
+		return nil
+	}
+	if _, err := c.store.DB.Exec(`DELETE FROM http_cache WHERE fetched_at < ?`, now.Add(-CacheMaxAge).Unix()); err != nil {
+		return err
+	}
+	c.prunedAt = now
+	return nil
+}
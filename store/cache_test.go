@@ -0,0 +1,77 @@
+package store
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"polyapi/httpx"
+)
+
+func TestHTTPCache(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	cache := s.HTTPCache()
+
+	if _, ok, err := cache.Get("GET https://api.weather.gov/points/1,2"); ok || err != nil {
+		t.Fatalf("Get on an empty cache = %v, %v", ok, err)
+	}
+
+	entry := httpx.CacheEntry{
+		Key:         "GET https://api.weather.gov/points/1,2",
+		URL:         "https://api.weather.gov/points/1,2",
+		ContentType: "application/geo+json",
+		Body:        []byte(`{"properties": {}}`),
+		ETag:        `"abc"`,
+		FetchedAt:   time.Unix(1724683200, 0),
+	}
+	for i := 0; i < 2; i++ {
+		if err := cache.Put(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, ok, err := cache.Get(entry.Key)
+	if err != nil || !ok {
+		t.Fatalf("Get = %v, %v", ok, err)
+	}
+	if !bytes.Equal(got.Body, entry.Body) || got.ETag != entry.ETag || !got.FetchedAt.Equal(entry.FetchedAt) || got.ContentType != entry.ContentType {
+		t.Errorf("Get = %+v, want %+v", got, entry)
+	}
+}
+
+func TestHTTPCachePrune(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	old := httpx.CacheEntry{Key: "GET https://api.weather.gov/points/1,2", Body: []byte(`{}`), FetchedAt: time.Now().Add(-CacheMaxAge - time.Hour)}
+	recent := httpx.CacheEntry{Key: "GET https://api.weather.gov/points/3,4", Body: []byte(`{}`), FetchedAt: time.Now().Add(-CacheMaxAge / 2)}
+	cache := s.HTTPCache()
+	for _, entry := range []httpx.CacheEntry{old, recent} {
+		if err := cache.Put(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Within the hour the old response is kept, and the next run's first response deletes it
+	if _, ok, _ := cache.Get(old.Key); !ok {
+		t.Error("the old response was deleted within the hour")
+	}
+	if err := s.HTTPCache().Put(httpx.CacheEntry{Key: "GET https://api.weather.gov/points/5,6", Body: []byte(`{}`), FetchedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.HTTPCache().Get(old.Key); ok {
+		t.Error("the old response is still cached")
+	}
+	if _, ok, _ := s.HTTPCache().Get(recent.Key); !ok {
+		t.Error("the recent response was deleted")
+	}
+}
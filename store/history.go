@@ -0,0 +1,170 @@
+package store
+
+import (
+	"time"
+)
+
+// TemperatureReading is a temperature recorded for a saved address, in Fahrenheit.
+// Source is the NOAA station identifier, or "forecast" for the hourly forecast.
+type TemperatureReading struct {
+	AddressId   int
+	Temperature float64
+	Source      string
+	RecordedAt  time.Time
+}
+
+// PriceQuote is a stock quote recorded for a ticker symbol.
+type PriceQuote struct {
+	Ticker        string
+	Price         float64
+	Open          float64
+	High          float64
+	Low           float64
+	PreviousClose float64
+	Change        float64
+	ChangePercent float64
+	Volume        int64
+	TradingDay    string
+	Source        string
+	RecordedAt    time.Time
+}
+
+// AddTemperatureReading appends a reading to the address's temperature history.
+// A reading already recorded from the same source at the same time is ignored.
+func (s *Store) AddTemperatureReading(reading TemperatureReading) error {
+	_, err := s.DB.Exec(`
+		INSERT OR IGNORE INTO temperature_readings (address_id, temperature, source, recorded_at)
+		VALUES (?, ?, ?, ?)
+	`, reading.AddressId, reading.Temperature, reading.Source, reading.RecordedAt.UTC().Truncate(time.Second))
+	return err
+}
+
+// TemperatureReadings returns the readings for an address recorded between from and to, oldest first.
+func (s *Store) TemperatureReadings(addressId int, from, to time.Time) ([]TemperatureReading, error) {
+
+	rows, err := s.DB.Query(`
+		SELECT address_id, temperature, source, recorded_at FROM temperature_readings
+		WHERE address_id = ? AND recorded_at >= ? AND recorded_at < ?
+		ORDER BY recorded_at
+	`, addressId, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readings []TemperatureReading
+	for rows.Next() {
+		var reading TemperatureReading
+		if err := rows.Scan(&reading.AddressId, &reading.Temperature, &reading.Source, &reading.RecordedAt); err != nil {
+			return nil, err
+		}
+		readings = append(readings, reading)
+	}
+
+	return readings, rows.Err()
+}
+
+// AddPriceQuote appends a quote to the ticker's price history.
+// The same quote fetched again, e.g. outside trading hours, is ignored.
+func (s *Store) AddPriceQuote(quote PriceQuote) error {
+	_, err := s.DB.Exec(`
+		INSERT OR IGNORE INTO price_quotes (
+			ticker, price, open, high, low, previous_close, change, change_percent,
+			volume, trading_day, source, recorded_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, quote.Ticker, quote.Price, quote.Open, quote.High, quote.Low, quote.PreviousClose, quote.Change,
+		quote.ChangePercent, quote.Volume, quote.TradingDay, quote.Source, quote.RecordedAt.UTC().Truncate(time.Second))
+	return err
+}
+
+// PriceQuotes returns the quotes for a ticker symbol recorded between from and to, oldest first.
+func (s *Store) PriceQuotes(tickerSymbol string, from, to time.Time) ([]PriceQuote, error) {
+
+	rows, err := s.DB.Query(`
+		SELECT ticker, price, open, high, low, previous_close, change, change_percent,
+			volume, trading_day, source, recorded_at
+		FROM price_quotes
+		WHERE ticker = ? AND recorded_at >= ? AND recorded_at < ?
+		ORDER BY recorded_at
+	`, tickerSymbol, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quotes []PriceQuote
+	for rows.Next() {
+		var quote PriceQuote
+		err := rows.Scan(&quote.Ticker, &quote.Price, &quote.Open, &quote.High, &quote.Low, &quote.PreviousClose,
+			&quote.Change, &quote.ChangePercent, &quote.Volume, &quote.TradingDay, &quote.Source, &quote.RecordedAt)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, rows.Err()
+}
+
+// LatestTemperatureReadings returns the latest reading of each address with a
+// temperature history, ordered by address.
+func (s *Store) LatestTemperatureReadings() ([]TemperatureReading, error) {
+
+	rows, err := s.DB.Query(`
+		SELECT address_id, temperature, source, recorded_at FROM temperature_readings r
+		WHERE recorded_at = (SELECT MAX(recorded_at) FROM temperature_readings WHERE address_id = r.address_id)
+		ORDER BY address_id, source
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var readings []TemperatureReading
+	for rows.Next() {
+		var reading TemperatureReading
+		if err := rows.Scan(&reading.AddressId, &reading.Temperature, &reading.Source, &reading.RecordedAt); err != nil {
+			return nil, err
+		}
+		// Stations reporting at the same time give more than one latest reading
+		if n := len(readings); n > 0 && readings[n-1].AddressId == reading.AddressId {
+			continue
+		}
+		readings = append(readings, reading)
+	}
+
+	return readings, rows.Err()
+}
+
+// LatestPriceQuotes returns the latest quote of each ticker symbol with a price
+// history, ordered by ticker symbol.
+func (s *Store) LatestPriceQuotes() ([]PriceQuote, error) {
+
+	rows, err := s.DB.Query(`
+		SELECT ticker, price, open, high, low, previous_close, change, change_percent,
+			volume, trading_day, source, recorded_at
+		FROM price_quotes q
+		WHERE recorded_at = (SELECT MAX(recorded_at) FROM price_quotes WHERE ticker = q.ticker)
+		ORDER BY ticker
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quotes []PriceQuote
+	for rows.Next() {
+		var quote PriceQuote
+		err := rows.Scan(&quote.Ticker, &quote.Price, &quote.Open, &quote.High, &quote.Low, &quote.PreviousClose,
+			&quote.Change, &quote.ChangePercent, &quote.Volume, &quote.TradingDay, &quote.Source, &quote.RecordedAt)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(quotes); n > 0 && quotes[n-1].Ticker == quote.Ticker {
+			continue
+		}
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, rows.Err()
+}
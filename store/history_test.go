@@ -0,0 +1,100 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTemperatureReadings(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	address, err := s.SaveAddress("432 PARK AVE, NEW YORK, NY, 10022", 40.76, -73.97)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 8, 26, 14, 51, 0, 0, time.UTC)
+	for i, temperature := range []float64{75.9, 77.0, 73.4} {
+		reading := TemperatureReading{AddressId: address.Id, Temperature: temperature, Source: "KNYC", RecordedAt: start.Add(time.Duration(i) * time.Hour)}
+		if err := s.AddTemperatureReading(reading); err != nil {
+			t.Fatal(err)
+		}
+		// The same observation fetched again isn't recorded twice
+		if err := s.AddTemperatureReading(reading); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	readings, err := s.TemperatureReadings(address.Id, start.Add(time.Hour), start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readings) != 2 || readings[0].Temperature != 77.0 || !readings[1].RecordedAt.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("readings = %+v", readings)
+	}
+
+	// Deleting the address deletes its history
+	if _, err := s.DeleteAddress(address.Id); err != nil {
+		t.Fatal(err)
+	}
+	if readings, _ := s.TemperatureReadings(address.Id, start, start.Add(24*time.Hour)); len(readings) != 0 {
+		t.Errorf("readings after delete = %+v", readings)
+	}
+}
+
+func TestLatestReadings(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	newYork, err := s.SaveAddress("432 PARK AVE, NEW YORK, NY, 10022", 40.76, -73.97)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boston, err := s.SaveAddress("100 MAIN ST, BOSTON, MA, 02129", 42.37, -71.06)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 8, 26, 14, 51, 0, 0, time.UTC)
+	readings := []TemperatureReading{
+		{AddressId: newYork.Id, Temperature: 75.9, Source: "KNYC", RecordedAt: start.Add(time.Hour)},
+		{AddressId: newYork.Id, Temperature: 77.0, Source: "KNYC", RecordedAt: start},
+		{AddressId: boston.Id, Temperature: 68.0, Source: "KBOS", RecordedAt: start},
+	}
+	for _, reading := range readings {
+		if err := s.AddTemperatureReading(reading); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err := s.LatestTemperatureReadings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latest) != 2 || latest[0].Temperature != 75.9 || latest[1].Temperature != 68.0 {
+		t.Errorf("latest readings = %+v", latest)
+	}
+
+	for i, price := range []float64{226.05, 227.18} {
+		quote := PriceQuote{Ticker: "AAPL", Price: price, TradingDay: "2024-08-26", Source: "alphavantage", RecordedAt: start.Add(time.Duration(i) * time.Hour)}
+		if err := s.AddPriceQuote(quote); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	quotes, err := s.LatestPriceQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 1 || quotes[0].Price != 227.18 {
+		t.Errorf("latest quotes = %+v", quotes)
+	}
+}
@@ -0,0 +1,429 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Migration is a numbered change to the database schema. Up applies the change
+// and Down reverts it; each runs in a transaction with its schema_migrations row.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sql.Tx) error
+	Down    func(tx *sql.Tx) error
+}
+
+// MigrationStatus is a migration and when it was applied.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// migrations are the schema changes in the order they are applied.
+// Never edit an applied migration; add a new one instead.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create addresses and tickers",
+		Up: execSQL(`
+			CREATE TABLE IF NOT EXISTS addresses (
+				id INTEGER PRIMARY KEY,
+				address TEXT NOT NULL,
+				lat REAL NOT NULL,
+				lon REAL NOT NULL,
+				last_temperature TEXT,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+			CREATE TABLE IF NOT EXISTS tickers (
+				id INTEGER PRIMARY KEY,
+				ticker TEXT NOT NULL,
+				company_name TEXT NOT NULL,
+				sector TEXT NOT NULL,
+				industry TEXT NOT NULL,
+				exchange TEXT NOT NULL,
+				address TEXT NOT NULL,
+				official_site TEXT NOT NULL,
+				revenue_ttm REAL NOT NULL,
+				market_cap REAL NOT NULL,
+				fiscal_year_end TEXT NOT NULL,
+				last_price REAL NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+		`),
+		Down: execSQL(`
+			DROP TABLE tickers;
+			DROP TABLE addresses;
+		`),
+	},
+	{
+		// Databases created before migrations may already have these columns
+		Version: 2,
+		Name:    "add updated_at to addresses and tickers",
+		Up: func(tx *sql.Tx) error {
+			for _, table := range []string{"addresses", "tickers"} {
+				if err := addColumn(tx, table, "updated_at", "TIMESTAMP"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: execSQL(`
+			ALTER TABLE tickers DROP COLUMN updated_at;
+			ALTER TABLE addresses DROP COLUMN updated_at;
+		`),
+	},
+	{
+		Version: 3,
+		Name:    "create http_cache",
+		Up: execSQL(`
+			CREATE TABLE IF NOT EXISTS http_cache (
+				key TEXT PRIMARY KEY,
+				url TEXT NOT NULL,
+				content_type TEXT NOT NULL DEFAULT '',
+				body BLOB NOT NULL,
+				etag TEXT NOT NULL DEFAULT '',
+				last_modified TEXT NOT NULL DEFAULT '',
+				fetched_at INTEGER NOT NULL
+			);
+		`),
+		Down: execSQL(`DROP TABLE http_cache;`),
+	},
+	{
+		Version: 4,
+		Name:    "create temperature_readings and price_quotes",
+		Up: execSQL(`
+			CREATE TABLE temperature_readings (
+				id INTEGER PRIMARY KEY,
+				address_id INTEGER NOT NULL,
+				temperature REAL NOT NULL,
+				source TEXT NOT NULL,
+				recorded_at TIMESTAMP NOT NULL,
+				UNIQUE (address_id, source, recorded_at)
+			);
+			CREATE INDEX temperature_readings_address ON temperature_readings (address_id, recorded_at);
+			CREATE TABLE price_quotes (
+				id INTEGER PRIMARY KEY,
+				ticker TEXT NOT NULL,
+				price REAL NOT NULL,
+				open REAL NOT NULL,
+				high REAL NOT NULL,
+				low REAL NOT NULL,
+				previous_close REAL NOT NULL,
+				change REAL NOT NULL,
+				change_percent REAL NOT NULL,
+				volume INTEGER NOT NULL,
+				trading_day TEXT NOT NULL,
+				source TEXT NOT NULL,
+				recorded_at TIMESTAMP NOT NULL,
+				UNIQUE (ticker, trading_day, price, volume)
+			);
+			CREATE INDEX price_quotes_ticker ON price_quotes (ticker, recorded_at);
+		`),
+		Down: execSQL(`
+			DROP TABLE price_quotes;
+			DROP TABLE temperature_readings;
+		`),
+	},
+	{
+		Version: 5,
+		Name:    "create series_observations",
+		Up: execSQL(`
+			CREATE TABLE series_observations (
+				source TEXT NOT NULL,
+				series_id TEXT NOT NULL,
+				date TEXT NOT NULL,
+				value REAL NOT NULL,
+				fetched_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (source, series_id, date)
+			);
+		`),
+		Down: execSQL(`DROP TABLE series_observations;`),
+	},
+	{
+		Version: 6,
+		Name:    "create watchlist with the default BLS and FRED series",
+		Up: execSQL(`
+			CREATE TABLE watchlist (
+				id INTEGER PRIMARY KEY,
+				source TEXT NOT NULL,
+				series_id TEXT NOT NULL,
+				label TEXT NOT NULL,
+				frequency TEXT NOT NULL DEFAULT '',
+				units TEXT NOT NULL DEFAULT '',
+				UNIQUE (source, series_id)
+			);
+			INSERT INTO watchlist (source, series_id, label, frequency, units) VALUES
+				('bls', 'PCU22112222112241', 'Producer Price Index (PPI) Data', 'Monthly', 'Index'),
+				('bls', 'CUUR0000SA0L1E', 'Consumer Price Index (CPI) Data, less food & energy', 'Monthly', 'Index'),
+				('bls', 'CUSR0000SA0', 'Consumer Price Index (CPI) Data', 'Monthly', 'Index'),
+				('bls', 'LNS14000000', 'Unemployment Rate Data', 'Monthly', 'Percent'),
+				('bls', 'CES0000000001', 'Nonfarm Payroll Data', 'Monthly', 'Thousands of Persons'),
+				('fred', 'FEDFUNDS', 'Federal Funds Rate', 'Monthly', 'Percent'),
+				('fred', 'ICSA', 'Initial Claims for Unemployment Insurance', 'Weekly, Ending Saturday', 'Number'),
+				('fred', 'RSAFS', 'Retail Sales', 'Monthly', 'Millions of Dollars'),
+				('fred', 'UNRATE', 'Unemployment Rate', 'Monthly', 'Percent'),
+				('fred', 'GDP', 'Gross Domestic Product', 'Quarterly', 'Billions of Dollars'),
+				('fred', 'PCE', 'Personal Consumption Expenditures', 'Monthly', 'Billions of Dollars'),
+				('fred', 'DTB1YR', '1-Year Treasury Bill', 'Daily', 'Percent'),
+				('fred', 'TB3MS', '3-Month Treasury Bill', 'Monthly', 'Percent'),
+				('fred', 'DTB4WK', '4-Week Treasury Bill', 'Daily', 'Percent'),
+				('fred', 'DTB6', '6-Month Treasury Bill', 'Daily', 'Percent');
+		`),
+		Down: execSQL(`DROP TABLE watchlist;`),
+	},
+	{
+		Version: 7,
+		Name:    "create series_vintages",
+		Up: execSQL(`
+			CREATE TABLE series_vintages (
+				source TEXT NOT NULL,
+				series_id TEXT NOT NULL,
+				date TEXT NOT NULL,
+				realtime_start TEXT NOT NULL,
+				realtime_end TEXT NOT NULL,
+				value REAL NOT NULL,
+				fetched_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (source, series_id, date, realtime_start)
+			);
+		`),
+		Down: execSQL(`DROP TABLE series_vintages;`),
+	},
+	{
+		Version: 8,
+		Name:    "add release_id and fetched_at to watchlist",
+		Up: execSQL(`
+			ALTER TABLE watchlist ADD COLUMN release_id INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE watchlist ADD COLUMN fetched_at TIMESTAMP;
+			UPDATE watchlist SET release_id = 46 WHERE source = 'bls' AND series_id = 'PCU22112222112241';
+			UPDATE watchlist SET release_id = 10 WHERE source = 'bls' AND series_id IN ('CUUR0000SA0L1E', 'CUSR0000SA0');
+			UPDATE watchlist SET release_id = 50 WHERE source = 'bls' AND series_id IN ('LNS14000000', 'CES0000000001');
+			UPDATE watchlist SET release_id = 18 WHERE source = 'fred' AND series_id IN ('FEDFUNDS', 'DTB1YR', 'TB3MS', 'DTB4WK', 'DTB6');
+			UPDATE watchlist SET release_id = 180 WHERE source = 'fred' AND series_id = 'ICSA';
+			UPDATE watchlist SET release_id = 9 WHERE source = 'fred' AND series_id = 'RSAFS';
+			UPDATE watchlist SET release_id = 50 WHERE source = 'fred' AND series_id = 'UNRATE';
+			UPDATE watchlist SET release_id = 53 WHERE source = 'fred' AND series_id = 'GDP';
+			UPDATE watchlist SET release_id = 54 WHERE source = 'fred' AND series_id = 'PCE';
+		`),
+		Down: execSQL(`
+			ALTER TABLE watchlist DROP COLUMN fetched_at;
+			ALTER TABLE watchlist DROP COLUMN release_id;
+		`),
+	},
+	{
+		Version: 9,
+		Name:    "create yield_curves",
+		Up: execSQL(`
+			CREATE TABLE yield_curves (
+				date TEXT NOT NULL,
+				maturity TEXT NOT NULL,
+				yield REAL NOT NULL,
+				fetched_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (date, maturity)
+			);
+		`),
+		Down: execSQL(`DROP TABLE yield_curves;`),
+	},
+	{
+		Version: 10,
+		Name:    "add period and preliminary to series_observations",
+		Up: execSQL(`
+			ALTER TABLE series_observations ADD COLUMN period TEXT NOT NULL DEFAULT '';
+			ALTER TABLE series_observations ADD COLUMN preliminary BOOLEAN NOT NULL DEFAULT 0;
+			UPDATE series_observations SET period = 'M' || substr(date, 6, 2) WHERE source = 'bls';
+		`),
+		Down: execSQL(`
+			ALTER TABLE series_observations DROP COLUMN preliminary;
+			ALTER TABLE series_observations DROP COLUMN period;
+		`),
+	},
+	{
+		Version: 11,
+		Name:    "add state and metro area to addresses",
+		Up: execSQL(`
+			ALTER TABLE addresses ADD COLUMN state_fips TEXT NOT NULL DEFAULT '';
+			ALTER TABLE addresses ADD COLUMN state TEXT NOT NULL DEFAULT '';
+			ALTER TABLE addresses ADD COLUMN cbsa TEXT NOT NULL DEFAULT '';
+			ALTER TABLE addresses ADD COLUMN metro TEXT NOT NULL DEFAULT '';
+		`),
+		Down: execSQL(`
+			ALTER TABLE addresses DROP COLUMN metro;
+			ALTER TABLE addresses DROP COLUMN cbsa;
+			ALTER TABLE addresses DROP COLUMN state;
+			ALTER TABLE addresses DROP COLUMN state_fips;
+		`),
+	},
+	{
+		Version: 12,
+		Name:    "create treasury_rates",
+		Up: execSQL(`
+			CREATE TABLE treasury_rates (
+				record_date TEXT NOT NULL,
+				security_type TEXT NOT NULL,
+				security TEXT NOT NULL,
+				rate REAL NOT NULL,
+				line INTEGER NOT NULL,
+				fetched_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (record_date, security_type, security)
+			);
+		`),
+		Down: execSQL(`DROP TABLE treasury_rates;`),
+	},
+}
+
+// execSQL returns a migration step that runs the SQL statements.
+func execSQL(statements string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(statements)
+		return err
+	}
+}
+
+// addColumn adds a column to a table unless the table already has it.
+// Table and column names can't be bound as parameters in ALTER TABLE,
+// so they must be constants.
+func addColumn(tx *sql.Tx, table, column, columnType string) error {
+
+	var count int
+	err := tx.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("error checking for existing column: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, columnType))
+	if err != nil {
+		return fmt.Errorf("error adding column %s to %s: %w", column, table, err)
+	}
+	return nil
+}
+
+// createMigrationsTable creates the table recording the applied migrations.
+func (s *Store) createMigrationsTable() error {
+	_, err := s.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	return err
+}
+
+// MigrationStatus returns every migration and whether it has been applied.
+func (s *Store) MigrationStatus() ([]MigrationStatus, error) {
+
+	if err := s.createMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appliedAt := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range migrations {
+		at, applied := appliedAt[migration.Version]
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: applied, AppliedAt: at})
+	}
+
+	return statuses, nil
+}
+
+// Migrate applies the pending migrations in order and returns them.
+func (s *Store) Migrate() ([]Migration, error) {
+
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, status := range statuses {
+		if status.Applied {
+			continue
+		}
+
+		err := s.inTx(func(tx *sql.Tx) error {
+			if err := status.Up(tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", status.Version, status.Name)
+			return err
+		})
+		if err != nil {
+			return applied, fmt.Errorf("error applying migration %d (%s): %w", status.Version, status.Name, err)
+		}
+
+		applied = append(applied, status.Migration)
+	}
+
+	return applied, nil
+}
+
+// Rollback reverts the last steps applied migrations, newest first, and returns them.
+func (s *Store) Rollback(steps int) ([]Migration, error) {
+
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledBack []Migration
+	for i := len(statuses) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		status := statuses[i]
+		if !status.Applied {
+			continue
+		}
+
+		err := s.inTx(func(tx *sql.Tx) error {
+			if err := status.Down(tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", status.Version)
+			return err
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("error rolling back migration %d (%s): %w", status.Version, status.Name, err)
+		}
+
+		rolledBack = append(rolledBack, status.Migration)
+	}
+
+	return rolledBack, nil
+}
+
+// inTx runs fn in a transaction, committing it if fn succeeds.
+func (s *Store) inTx(fn func(tx *sql.Tx) error) error {
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
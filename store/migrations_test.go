@@ -0,0 +1,86 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateAndRollback(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	statuses, err := s.MigrationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			t.Errorf("migration %d not applied", status.Version)
+		}
+	}
+
+	// Migrating again is a no-op
+	if applied, err := s.Migrate(); err != nil || len(applied) != 0 {
+		t.Errorf("Migrate = %d migrations, %v", len(applied), err)
+	}
+
+	rolledBack, err := s.Rollback(len(migrations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rolledBack) != len(migrations) || rolledBack[0].Version != migrations[len(migrations)-1].Version {
+		t.Errorf("Rollback = %v", rolledBack)
+	}
+	var tables int
+	s.DB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name != 'schema_migrations'").Scan(&tables)
+	if tables != 0 {
+		t.Errorf("%d tables left after rolling back", tables)
+	}
+
+	if applied, err := s.Migrate(); err != nil || len(applied) != len(migrations) {
+		t.Errorf("Migrate = %d migrations, %v", len(applied), err)
+	}
+}
+
+func TestMigrateExistingDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "polyapi.db")
+
+	// A database created before migrations, which already has updated_at
+	s, err := OpenWithoutMigrating(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.DB.Exec(`
+		CREATE TABLE addresses (
+			id INTEGER PRIMARY KEY,
+			address TEXT NOT NULL,
+			lat REAL NOT NULL,
+			lon REAL NOT NULL,
+			last_temperature TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		INSERT INTO addresses (address, lat, lon) VALUES ('432 PARK AVE, NEW YORK, NY, 10022', 40.76, -73.97);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	addresses, err := s.Addresses()
+	if err != nil || len(addresses) != 1 {
+		t.Fatalf("Addresses = %v, %v", addresses, err)
+	}
+	if _, err := s.Tickers(); err != nil {
+		t.Errorf("Tickers: %v", err)
+	}
+}
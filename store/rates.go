@@ -0,0 +1,69 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SecurityRate is the average interest rate of a Treasury security in a month, in percent.
+type SecurityRate struct {
+	Type     string
+	Security string
+	Rate     float64
+}
+
+// TreasuryRates are the average interest rates of the Treasury securities in a month,
+// in the order of the Treasury's report. RecordDate is formatted as YYYY-MM-DD.
+type TreasuryRates struct {
+	RecordDate string
+	Securities []SecurityRate
+}
+
+// SaveTreasuryRates saves a month's rates in a single transaction. A rate already
+// stored for the same month and security is replaced.
+func (s *Store) SaveTreasuryRates(rates TreasuryRates) error {
+
+	fetchedAt := time.Now().UTC().Truncate(time.Second)
+
+	return s.inTx(func(tx *sql.Tx) error {
+		for i, rate := range rates.Securities {
+			_, err := tx.Exec(`
+				INSERT OR REPLACE INTO treasury_rates (record_date, security_type, security, rate, line, fetched_at)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, rates.RecordDate, rate.Type, rate.Security, rate.Rate, i, fetchedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LatestTreasuryRates returns the rates of the latest stored month, or false if
+// none are stored.
+func (s *Store) LatestTreasuryRates() (TreasuryRates, bool, error) {
+
+	rows, err := s.DB.Query(`
+		SELECT record_date, security_type, security, rate FROM treasury_rates
+		WHERE record_date = (SELECT MAX(record_date) FROM treasury_rates)
+		ORDER BY line
+	`)
+	if err != nil {
+		return TreasuryRates{}, false, err
+	}
+	defer rows.Close()
+
+	var rates TreasuryRates
+	for rows.Next() {
+		var rate SecurityRate
+		if err := rows.Scan(&rates.RecordDate, &rate.Type, &rate.Security, &rate.Rate); err != nil {
+			return TreasuryRates{}, false, err
+		}
+		rates.Securities = append(rates.Securities, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return TreasuryRates{}, false, err
+	}
+
+	return rates, len(rates.Securities) > 0, nil
+}
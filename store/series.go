@@ -0,0 +1,96 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Observation is a dated value of an economic data series such as a FRED or BLS series.
+// Date is formatted as YYYY-MM-DD. Period is the source's own period, e.g. BLS's M07
+// or Q02, if it has one, and Preliminary is set for values that will be revised.
+type Observation struct {
+	Source      string
+	SeriesID    string
+	Date        string
+	Value       float64
+	Period      string
+	Preliminary bool
+}
+
+// SaveObservations saves observations in a single transaction. An observation
+// already stored for the same date is replaced, so revised and final values are kept.
+func (s *Store) SaveObservations(observations []Observation) error {
+
+	fetchedAt := time.Now().UTC().Truncate(time.Second)
+
+	return s.inTx(func(tx *sql.Tx) error {
+		for _, observation := range observations {
+			_, err := tx.Exec(`
+				INSERT OR REPLACE INTO series_observations (source, series_id, date, value, period, preliminary, fetched_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, observation.Source, observation.SeriesID, observation.Date, observation.Value, observation.Period, observation.Preliminary, fetchedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LatestObservationDate returns the date of the latest stored observation of a
+// series, or an empty string if none are stored.
+func (s *Store) LatestObservationDate(source, seriesID string) (string, error) {
+
+	var date sql.NullString
+	err := s.DB.QueryRow(`
+		SELECT MAX(date) FROM series_observations WHERE source = ? AND series_id = ?
+	`, source, seriesID).Scan(&date)
+
+	return date.String, err
+}
+
+// Observations returns the stored observations of a series, oldest first.
+func (s *Store) Observations(source, seriesID string) ([]Observation, error) {
+
+	rows, err := s.DB.Query(`
+		SELECT source, series_id, date, value, period, preliminary FROM series_observations
+		WHERE source = ? AND series_id = ?
+		ORDER BY date
+	`, source, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var observations []Observation
+	for rows.Next() {
+		var observation Observation
+		err := rows.Scan(&observation.Source, &observation.SeriesID, &observation.Date, &observation.Value, &observation.Period, &observation.Preliminary)
+		if err != nil {
+			return nil, err
+		}
+		observations = append(observations, observation)
+	}
+
+	return observations, rows.Err()
+}
+
+// LatestObservation returns the latest stored observation of a series, or false
+// if none are stored.
+func (s *Store) LatestObservation(source, seriesID string) (Observation, bool, error) {
+
+	observation := Observation{Source: source, SeriesID: seriesID}
+	err := s.DB.QueryRow(`
+		SELECT date, value FROM series_observations
+		WHERE source = ? AND series_id = ?
+		ORDER BY date DESC LIMIT 1
+	`, source, seriesID).Scan(&observation.Date, &observation.Value)
+	if err == sql.ErrNoRows {
+		return Observation{}, false, nil
+	}
+	if err != nil {
+		return Observation{}, false, err
+	}
+
+	return observation, true, nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestObservations(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if latest, err := s.LatestObservationDate("fred", "UNRATE"); err != nil || latest != "" {
+		t.Errorf("LatestObservationDate = %q, %v; want none", latest, err)
+	}
+
+	err = s.SaveObservations([]Observation{
+		{Source: "fred", SeriesID: "UNRATE", Date: "2024-07-01", Value: 4.3},
+		{Source: "fred", SeriesID: "UNRATE", Date: "2024-06-01", Value: 4.1},
+		{Source: "bls", SeriesID: "LNS14000000", Date: "2024-08-01", Value: 4.2, Period: "M08", Preliminary: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A revised value replaces the stored one
+	if err := s.SaveObservations([]Observation{{Source: "fred", SeriesID: "UNRATE", Date: "2024-07-01", Value: 4.2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if latest, err := s.LatestObservationDate("fred", "UNRATE"); err != nil || latest != "2024-07-01" {
+		t.Errorf("LatestObservationDate = %q, %v; want 2024-07-01", latest, err)
+	}
+
+	observations, err := s.Observations("fred", "UNRATE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(observations) != 2 || observations[0].Date != "2024-06-01" || observations[1].Value != 4.2 {
+		t.Errorf("observations = %+v", observations)
+	}
+
+	bls, err := s.Observations("bls", "LNS14000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bls) != 1 || bls[0].Period != "M08" || !bls[0].Preliminary {
+		t.Errorf("BLS observations = %+v", bls)
+	}
+}
@@ -0,0 +1,59 @@
+// Package store keeps saved addresses, ticker symbols, their history, economic
+// series observations and cached API responses in the local SQLite database.
+package store
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// DefaultPath is the database file used by the polyapi CLI.
+const DefaultPath = "./db/polyapi.db"
+
+// Store is the polyapi SQLite database.
+type Store struct {
+	DB *sql.DB
+}
+
+// Open opens the database at path, creating the file if it doesn't exist,
+// and applies any pending migrations.
+func Open(path string) (*Store, error) {
+
+	s, err := OpenWithoutMigrating(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Migrate(); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// OpenWithoutMigrating opens the database at path, creating the file if it doesn't
+// exist, without changing its schema. It is used to inspect and roll back migrations.
+func OpenWithoutMigrating(path string) (*Store, error) {
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	// Providers save from several goroutines at once, so wait for the database to be
+	// unlocked and take the write lock when a transaction begins to avoid deadlocks
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{DB: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.DB.Close()
+}
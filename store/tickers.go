@@ -0,0 +1,115 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Ticker is a ticker symbol saved for re-use.
+type Ticker struct {
+	CompanyName string
+	Ticker      string
+	Exchange    string
+	LastPrice   string
+	UpdatedAt   string
+	Id          int
+}
+
+// Company is the company overview saved with a new ticker symbol.
+type Company struct {
+	Name                 string
+	Sector               string
+	Industry             string
+	Exchange             string
+	Address              string
+	OfficialSite         string
+	RevenueTTM           string
+	MarketCapitalization string
+	FiscalYearEnd        string
+}
+
+// Tickers returns the unique saved ticker symbols.
+func (s *Store) Tickers() ([]Ticker, error) {
+
+	// Retrieve unique ticker symbols from the database
+	rows, err := s.DB.Query("SELECT id, company_name, ticker, exchange, last_price, updated_at FROM tickers GROUP BY ticker")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickers []Ticker
+
+	for rows.Next() {
+		var ticker Ticker
+		var updatedAt interface{}
+		err := rows.Scan(&ticker.Id, &ticker.CompanyName, &ticker.Ticker, &ticker.Exchange, &ticker.LastPrice, &updatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if t, ok := updatedAt.(time.Time); ok {
+			ticker.UpdatedAt = t.Format("2006-01-02T15:04:05")
+		}
+		tickers = append(tickers, ticker)
+	}
+
+	return tickers, rows.Err()
+}
+
+// SaveTicker saves the last price of a ticker symbol.
+// For a first time quote, the ticker is inserted with its company overview;
+// otherwise only the last price is updated.
+func (s *Store) SaveTicker(tickerSymbol string, company Company, lastPrice float64) error {
+
+	var id int
+	err := s.DB.QueryRow("SELECT id FROM tickers WHERE ticker = ? LIMIT 1", tickerSymbol).Scan(&id)
+	if err == sql.ErrNoRows {
+
+		// Insert data into database
+		_, err = s.DB.Exec(`
+            INSERT INTO tickers (
+                ticker, company_name, sector, industry, exchange, address, official_site, 
+                revenue_ttm, market_cap, fiscal_year_end, last_price, updated_at
+            ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+        `, tickerSymbol, company.Name, company.Sector, company.Industry, company.Exchange,
+			company.Address, company.OfficialSite, company.RevenueTTM, company.MarketCapitalization,
+			company.FiscalYearEnd, lastPrice)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	// Update data in database
+	_, err = s.DB.Exec(`
+        UPDATE tickers SET
+            last_price = ?, updated_at = CURRENT_TIMESTAMP
+        WHERE ticker = ?
+    `, lastPrice, tickerSymbol)
+	return err
+}
+
+// DeleteTicker deletes a ticker symbol and its price history from the database.
+// It reports whether the ticker symbol was found.
+func (s *Store) DeleteTicker(id int) (bool, error) {
+
+	var rowsAffected int64
+	err := s.inTx(func(tx *sql.Tx) error {
+
+		_, err := tx.Exec("DELETE FROM price_quotes WHERE ticker IN (SELECT ticker FROM tickers WHERE id = ?)", id)
+		if err != nil {
+			return err
+		}
+
+		// Delete the ticker from the database
+		result, err := tx.Exec("DELETE FROM tickers WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err = result.RowsAffected()
+		return err
+	})
+
+	return rowsAffected > 0, err
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Vintage is the value of an observation as published from RealtimeStart until it was
+// revised on RealtimeEnd. The current vintage ends on 9999-12-31. Dates are YYYY-MM-DD.
+type Vintage struct {
+	Source        string
+	SeriesID      string
+	Date          string
+	RealtimeStart string
+	RealtimeEnd   string
+	Value         float64
+}
+
+// SaveVintages saves vintages in a single transaction. A vintage already stored with the
+// same release date is replaced, so its end date is updated when it's revised.
+func (s *Store) SaveVintages(vintages []Vintage) error {
+
+	fetchedAt := time.Now().UTC().Truncate(time.Second)
+
+	return s.inTx(func(tx *sql.Tx) error {
+		for _, vintage := range vintages {
+			_, err := tx.Exec(`
+				INSERT OR REPLACE INTO series_vintages (source, series_id, date, realtime_start, realtime_end, value, fetched_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, vintage.Source, vintage.SeriesID, vintage.Date, vintage.RealtimeStart, vintage.RealtimeEnd, vintage.Value, fetchedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Vintages returns the stored vintages of the observations of a series between from
+// and to, ordered by observation date and then release date.
+func (s *Store) Vintages(source, seriesID, from, to string) ([]Vintage, error) {
+
+	rows, err := s.DB.Query(`
+		SELECT source, series_id, date, realtime_start, realtime_end, value FROM series_vintages
+		WHERE source = ? AND series_id = ? AND date >= ? AND date <= ?
+		ORDER BY date, realtime_start
+	`, source, seriesID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vintages []Vintage
+	for rows.Next() {
+		var vintage Vintage
+		err := rows.Scan(&vintage.Source, &vintage.SeriesID, &vintage.Date, &vintage.RealtimeStart, &vintage.RealtimeEnd, &vintage.Value)
+		if err != nil {
+			return nil, err
+		}
+		vintages = append(vintages, vintage)
+	}
+
+	return vintages, rows.Err()
+}
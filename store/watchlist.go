@@ -0,0 +1,95 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Series is an economic data series on the watchlist, e.g. a FRED or BLS series.
+// ReleaseID is the FRED release that publishes it, or 0 if it isn't known yet, and
+// FetchedAt is when its observations were last fetched, or zero if they never were.
+type Series struct {
+	Source    string
+	SeriesID  string
+	Label     string
+	Frequency string
+	Units     string
+	ReleaseID int
+	FetchedAt time.Time
+}
+
+// Watchlist returns the series on the watchlist from source, or from every
+// source if source is empty, in the order they were added.
+func (s *Store) Watchlist(source string) ([]Series, error) {
+
+	rows, err := s.DB.Query(`
+		SELECT source, series_id, label, frequency, units, release_id, fetched_at FROM watchlist
+		WHERE ? = '' OR source = ?
+		ORDER BY source, id
+	`, source, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watchlist []Series
+	for rows.Next() {
+		var series Series
+		var fetchedAt sql.NullTime
+		err := rows.Scan(&series.Source, &series.SeriesID, &series.Label, &series.Frequency, &series.Units, &series.ReleaseID, &fetchedAt)
+		if err != nil {
+			return nil, err
+		}
+		series.FetchedAt = fetchedAt.Time
+		watchlist = append(watchlist, series)
+	}
+
+	return watchlist, rows.Err()
+}
+
+// AddSeries adds a series to the watchlist, or updates its label, frequency, units
+// and release if it's already on it. A release ID of 0 keeps the series' release, so
+// re-adding a series doesn't drop it from the release calendar.
+func (s *Store) AddSeries(series Series) error {
+	_, err := s.DB.Exec(`
+		INSERT INTO watchlist (source, series_id, label, frequency, units, release_id) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (source, series_id) DO UPDATE SET
+			label = excluded.label, frequency = excluded.frequency, units = excluded.units,
+			release_id = CASE WHEN excluded.release_id = 0 THEN watchlist.release_id ELSE excluded.release_id END
+	`, series.Source, series.SeriesID, series.Label, series.Frequency, series.Units, series.ReleaseID)
+	return err
+}
+
+// RemoveSeries removes a series from the watchlist. Its stored observations are kept.
+func (s *Store) RemoveSeries(source, seriesID string) error {
+
+	result, err := s.DB.Exec("DELETE FROM watchlist WHERE source = ? AND series_id = ?", source, seriesID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return fmt.Errorf("%s series %s is not on the watchlist", source, seriesID)
+	}
+
+	return nil
+}
+
+// SetSeriesRelease sets the FRED release that publishes a series on the watchlist.
+func (s *Store) SetSeriesRelease(source, seriesID string, releaseID int) error {
+	_, err := s.DB.Exec("UPDATE watchlist SET release_id = ? WHERE source = ? AND series_id = ?", releaseID, source, seriesID)
+	return err
+}
+
+// MarkSeriesFetched records that the observations of a series were just fetched.
+// Series that aren't on the watchlist are ignored.
+func (s *Store) MarkSeriesFetched(source, seriesID string) error {
+	_, err := s.DB.Exec("UPDATE watchlist SET fetched_at = ? WHERE source = ? AND series_id = ?",
+		time.Now().UTC().Truncate(time.Second), source, seriesID)
+	return err
+}
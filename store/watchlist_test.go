@@ -0,0 +1,87 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchlist(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The migration adds the series polyapi used to hard-code
+	fred, err := s.Watchlist("fred")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fred) != 10 || fred[0].SeriesID != "FEDFUNDS" || fred[4].Frequency != "Quarterly" {
+		t.Errorf("fred watchlist = %+v", fred)
+	}
+
+	series := Series{Source: "bls", SeriesID: "LNU04000000", Label: "Unemployment Rate", Frequency: "Monthly", Units: "Percent"}
+	if err := s.AddSeries(series); err != nil {
+		t.Fatal(err)
+	}
+	// Adding a series again updates it
+	series.Label = "Unemployment Rate, not seasonally adjusted"
+	if err := s.AddSeries(series); err != nil {
+		t.Fatal(err)
+	}
+
+	bls, err := s.Watchlist("bls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bls) != 6 || bls[5] != series {
+		t.Errorf("bls watchlist = %+v", bls)
+	}
+
+	// Fetching a series records when, and the release publishing it can be set
+	if err := s.MarkSeriesFetched("bls", "LNU04000000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetSeriesRelease("bls", "LNU04000000", 50); err != nil {
+		t.Fatal(err)
+	}
+	bls, err = s.Watchlist("bls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bls[5].ReleaseID != 50 || time.Since(bls[5].FetchedAt) > time.Minute {
+		t.Errorf("fetched series = %+v", bls[5])
+	}
+	if fred[0].ReleaseID != 18 || !fred[0].FetchedAt.IsZero() {
+		t.Errorf("seeded series = %+v", fred[0])
+	}
+
+	// Re-adding a seeded series without its release keeps it on the release calendar
+	if err := s.AddSeries(Series{Source: "fred", SeriesID: "FEDFUNDS", Label: "Federal Funds Effective Rate", Frequency: "Monthly", Units: "Percent"}); err != nil {
+		t.Fatal(err)
+	}
+	fred, err = s.Watchlist("fred")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fred[0].ReleaseID != 18 || fred[0].Label != "Federal Funds Effective Rate" {
+		t.Errorf("re-added series = %+v", fred[0])
+	}
+
+	if err := s.RemoveSeries("bls", "LNU04000000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveSeries("bls", "LNU04000000"); err == nil {
+		t.Error("expected an error removing a series that isn't on the watchlist")
+	}
+
+	all, err := s.Watchlist("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 15 {
+		t.Errorf("got %d series, want 15", len(all))
+	}
+}
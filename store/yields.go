@@ -0,0 +1,78 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Yield is the par yield of a Treasury maturity on a date, in percent.
+type Yield struct {
+	Maturity string
+	Yield    float64
+}
+
+// YieldCurve is the daily Treasury par yield curve. Date is formatted as YYYY-MM-DD.
+type YieldCurve struct {
+	Date   string
+	Yields []Yield
+}
+
+// SaveYieldCurves saves yield curves in a single transaction. A yield already stored
+// for the same date and maturity is replaced.
+func (s *Store) SaveYieldCurves(curves []YieldCurve) error {
+
+	fetchedAt := time.Now().UTC().Truncate(time.Second)
+
+	return s.inTx(func(tx *sql.Tx) error {
+		for _, curve := range curves {
+			for _, yield := range curve.Yields {
+				_, err := tx.Exec(`
+					INSERT OR REPLACE INTO yield_curves (date, maturity, yield, fetched_at)
+					VALUES (?, ?, ?, ?)
+				`, curve.Date, yield.Maturity, yield.Yield, fetchedAt)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// LatestYieldCurveDate returns the date of the latest stored yield curve, or an
+// empty string if none are stored.
+func (s *Store) LatestYieldCurveDate() (string, error) {
+
+	var date sql.NullString
+	err := s.DB.QueryRow("SELECT MAX(date) FROM yield_curves").Scan(&date)
+
+	return date.String, err
+}
+
+// YieldCurveOn returns the latest stored yield curve on or before date, or false
+// if there is none. Its yields are in no particular order.
+func (s *Store) YieldCurveOn(date string) (YieldCurve, bool, error) {
+
+	rows, err := s.DB.Query(`
+		SELECT date, maturity, yield FROM yield_curves
+		WHERE date = (SELECT MAX(date) FROM yield_curves WHERE date <= ?)
+	`, date)
+	if err != nil {
+		return YieldCurve{}, false, err
+	}
+	defer rows.Close()
+
+	var curve YieldCurve
+	for rows.Next() {
+		var yield Yield
+		if err := rows.Scan(&curve.Date, &yield.Maturity, &yield.Yield); err != nil {
+			return YieldCurve{}, false, err
+		}
+		curve.Yields = append(curve.Yields, yield)
+	}
+	if err := rows.Err(); err != nil {
+		return YieldCurve{}, false, err
+	}
+
+	return curve, len(curve.Yields) > 0, nil
+}
@@ -0,0 +1,135 @@
+package treasury
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"polyapi/httpx"
+	"polyapi/store"
+)
+
+// DefaultYieldCurveBaseURL is the base URL of the Treasury's daily interest rate data,
+// which isn't available from the fiscal data API.
+const DefaultYieldCurveBaseURL = "https://home.treasury.gov"
+
+// YieldCurves gets the daily par yield curves of a year, newest first.
+func (c *Client) YieldCurves(ctx context.Context, year int) ([]store.YieldCurve, error) {
+
+	params := url.Values{
+		"type":                 {"daily_treasury_yield_curve"},
+		"field_tdr_date_value": {strconv.Itoa(year)},
+		"_format":              {"csv"},
+	}
+	rawURL := fmt.Sprintf("%s/resource-center/data-chart-center/interest-rates/daily-treasury-rates.csv/%d/all?%s", c.YieldCurveBaseURL, year, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := httpx.CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the %d yield curves: %w", year, err)
+	}
+
+	return parseYieldCurves(records)
+}
+
+// parseYieldCurves parses the yield curve CSV, whose header is "Date" followed by the
+// maturities, e.g. "1 Mo" or "10 Yr". Maturities that weren't issued yet are empty.
+func parseYieldCurves(records [][]string) ([]store.YieldCurve, error) {
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	maturities := make([]string, len(header))
+	for i, name := range header[1:] {
+		maturity, err := parseMaturity(name)
+		if err != nil {
+			return nil, err
+		}
+		maturities[i+1] = maturity
+	}
+
+	var curves []store.YieldCurve
+	for _, record := range records[1:] {
+		date, err := time.Parse("01/02/2006", record[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid yield curve date %q: %w", record[0], err)
+		}
+
+		curve := store.YieldCurve{Date: date.Format("2006-01-02")}
+		for i := 1; i < len(record) && i < len(maturities); i++ {
+			value, err := strconv.ParseFloat(record[i], 64)
+			if err != nil {
+				continue
+			}
+			curve.Yields = append(curve.Yields, store.Yield{Maturity: maturities[i], Yield: value})
+		}
+		curves = append(curves, curve)
+	}
+
+	return curves, nil
+}
+
+// parseMaturity converts a CSV column such as "3 Mo", "1.5 Month" or "10 Yr" to
+// a maturity such as "3M", "1.5M" or "10Y".
+func parseMaturity(name string) (string, error) {
+
+	number, unit, ok := strings.Cut(strings.TrimSpace(name), " ")
+	if _, err := strconv.ParseFloat(number, 64); !ok || err != nil {
+		return "", fmt.Errorf("unknown yield curve maturity: %q", name)
+	}
+
+	switch {
+	case strings.HasPrefix(unit, "Wk"), strings.HasPrefix(unit, "Week"):
+		return number + "W", nil
+	case strings.HasPrefix(unit, "Mo"):
+		return number + "M", nil
+	case strings.HasPrefix(unit, "Yr"), strings.HasPrefix(unit, "Year"):
+		return number + "Y", nil
+	}
+
+	return "", fmt.Errorf("unknown yield curve maturity: %q", name)
+}
+
+// maturityYears returns the length of a maturity such as "3M" in years, to sort the curve.
+func maturityYears(maturity string) float64 {
+
+	if len(maturity) < 2 {
+		return 0
+	}
+	number, _ := strconv.ParseFloat(maturity[:len(maturity)-1], 64)
+
+	switch maturity[len(maturity)-1] {
+	case 'W':
+		return number / 52
+	case 'M':
+		return number / 12
+	}
+	return number
+}
+
+// sortYields sorts yields from the shortest maturity to the longest.
+func sortYields(yields []store.Yield) {
+	sort.Slice(yields, func(i, j int) bool {
+		return maturityYears(yields[i].Maturity) < maturityYears(yields[j].Maturity)
+	})
+}
@@ -0,0 +1,86 @@
+package treasury
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"polyapi/output"
+)
+
+// debtToPennyPath is the path of the Debt to the Penny dataset.
+const debtToPennyPath = "/services/api/fiscal_service/v2/accounting/od/debt_to_penny"
+
+// DebtRecord is the total public debt outstanding on a day, in dollars.
+type DebtRecord struct {
+	RecordDate        string `json:"record_date"`
+	DebtHeldPublicAmt string `json:"debt_held_public_amt"`
+	IntragovHoldAmt   string `json:"intragov_hold_amt"`
+	TotPubDebtOutAmt  string `json:"tot_pub_debt_out_amt"`
+}
+
+// DailyDebt is the public debt on a day in dollars, split into the debt held by the
+// public and intragovernmental holdings.
+type DailyDebt struct {
+	Date              string  `json:"date"`
+	HeldByPublic      float64 `json:"held_by_public"`
+	Intragovernmental float64 `json:"intragovernmental"`
+	Total             float64 `json:"total"`
+}
+
+// Debt is the daily total public debt outstanding from Debt to the Penny.
+type Debt struct {
+	Days []DailyDebt `json:"days"`
+}
+
+// Debt gets the daily public debt outstanding matching q.
+func (c *Client) Debt(ctx context.Context, q Query) (Debt, error) {
+
+	var debt Debt
+
+	records, err := query[DebtRecord](ctx, c, debtToPennyPath, q)
+	if err != nil {
+		return debt, err
+	}
+
+	for _, record := range records {
+		day := DailyDebt{Date: record.RecordDate}
+		day.HeldByPublic, _ = strconv.ParseFloat(record.DebtHeldPublicAmt, 64)
+		day.Intragovernmental, _ = strconv.ParseFloat(record.IntragovHoldAmt, 64)
+		if day.Total, err = strconv.ParseFloat(record.TotPubDebtOutAmt, 64); err != nil {
+			return debt, fmt.Errorf("invalid total public debt %q on %s", record.TotPubDebtOutAmt, record.RecordDate)
+		}
+		debt.Days = append(debt.Days, day)
+	}
+
+	return debt, nil
+}
+
+// PrintTable prints the debt of each day in trillions of dollars.
+func (d Debt) PrintTable(w io.Writer) {
+	fmt.Fprintln(w, "\nU.S. Total Public Debt Outstanding (Debt to the Penny, $ trillions):")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-12s %16s %18s %10s\n", "Date", "Held by public", "Intragovernmental", "Total")
+	for _, day := range d.Days {
+		fmt.Fprintf(w, "%-12s %16.3f %18.3f %10.3f\n", day.Date, day.HeldByPublic/1e12, day.Intragovernmental/1e12, day.Total/1e12)
+	}
+	if len(d.Days) == 0 {
+		fmt.Fprintln(w, "No records found")
+	}
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the debt columns, in dollars.
+func (d Debt) CSVHeader() []string {
+	return []string{"date", "held_by_public", "intragovernmental", "total"}
+}
+
+// CSVRows returns one row per day.
+func (d Debt) CSVRows() [][]string {
+	var rows [][]string
+	for _, day := range d.Days {
+		rows = append(rows, []string{day.Date, output.FormatFloat(day.HeldByPublic), output.FormatFloat(day.Intragovernmental), output.FormatFloat(day.Total)})
+	}
+	return rows
+}
@@ -0,0 +1,94 @@
+package treasury
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"polyapi/output"
+)
+
+// ratesOfExchangePath is the path of the Treasury Reporting Rates of Exchange dataset.
+const ratesOfExchangePath = "/services/api/fiscal_service/v1/accounting/od/rates_of_exchange"
+
+// ExchangeRecord is a Treasury reporting rate of exchange: the units of a foreign
+// currency per U.S. dollar, published quarterly.
+type ExchangeRecord struct {
+	RecordDate          string `json:"record_date"`
+	Country             string `json:"country"`
+	Currency            string `json:"currency"`
+	CountryCurrencyDesc string `json:"country_currency_desc"`
+	ExchangeRate        string `json:"exchange_rate"`
+	EffectiveDate       string `json:"effective_date"`
+}
+
+// ExchangeRate is the units of a country's currency per U.S. dollar from the effective date.
+type ExchangeRate struct {
+	Date          string  `json:"date"`
+	Country       string  `json:"country"`
+	Currency      string  `json:"currency"`
+	Rate          float64 `json:"rate"`
+	EffectiveDate string  `json:"effective_date"`
+}
+
+// ExchangeRates are the Treasury reporting rates of exchange used by federal agencies
+// to convert foreign currency amounts.
+type ExchangeRates struct {
+	Rates []ExchangeRate `json:"rates"`
+}
+
+// ExchangeRates gets the Treasury reporting rates of exchange matching q.
+func (c *Client) ExchangeRates(ctx context.Context, q Query) (ExchangeRates, error) {
+
+	var rates ExchangeRates
+
+	records, err := query[ExchangeRecord](ctx, c, ratesOfExchangePath, q)
+	if err != nil {
+		return rates, err
+	}
+
+	for _, record := range records {
+		rate, err := strconv.ParseFloat(record.ExchangeRate, 64)
+		if err != nil {
+			continue
+		}
+		rates.Rates = append(rates.Rates, ExchangeRate{
+			Date:          record.RecordDate,
+			Country:       record.Country,
+			Currency:      record.Currency,
+			Rate:          rate,
+			EffectiveDate: record.EffectiveDate,
+		})
+	}
+
+	return rates, nil
+}
+
+// PrintTable prints each rate with its country and currency.
+func (r ExchangeRates) PrintTable(w io.Writer) {
+	fmt.Fprintln(w, "\nTreasury Reporting Rates of Exchange (foreign currency per U.S. dollar):")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-12s %-30s %-20s %14s  %s\n", "Date", "Country", "Currency", "Rate", "Effective")
+	for _, rate := range r.Rates {
+		fmt.Fprintf(w, "%-12s %-30s %-20s %14s  %s\n", rate.Date, rate.Country, rate.Currency, output.FormatFloat(rate.Rate), rate.EffectiveDate)
+	}
+	if len(r.Rates) == 0 {
+		fmt.Fprintln(w, "No records found")
+	}
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the exchange rate columns.
+func (r ExchangeRates) CSVHeader() []string {
+	return []string{"date", "country", "currency", "rate", "effective_date"}
+}
+
+// CSVRows returns one row per rate.
+func (r ExchangeRates) CSVRows() [][]string {
+	var rows [][]string
+	for _, rate := range r.Rates {
+		rows = append(rows, []string{rate.Date, rate.Country, rate.Currency, output.FormatFloat(rate.Rate), rate.EffectiveDate})
+	}
+	return rows
+}
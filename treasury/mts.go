@@ -0,0 +1,98 @@
+package treasury
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"polyapi/output"
+)
+
+// mtsSummaryPath is the path of table 1 of the Monthly Treasury Statement, the summary
+// of receipts, outlays and the deficit or surplus.
+const mtsSummaryPath = "/services/api/fiscal_service/v1/accounting/mts/mts_table_1"
+
+// MTSRecord is a line of the Monthly Treasury Statement summary, in dollars. Each statement
+// has a line per month of the fiscal year so far and a year-to-date total; heading lines
+// such as "FY 2024" have "null" amounts.
+type MTSRecord struct {
+	RecordDate                string `json:"record_date"`
+	ClassificationDesc        string `json:"classification_desc"`
+	CurrentMonthGrossRcptAmt  string `json:"current_month_gross_rcpt_amt"`
+	CurrentMonthGrossOutlyAmt string `json:"current_month_gross_outly_amt"`
+	CurrentMonthDfctSurAmt    string `json:"current_month_dfct_sur_amt"`
+	RecordFiscalYear          string `json:"record_fiscal_year"`
+}
+
+// StatementLine is the receipts, outlays and deficit (negative) or surplus of a month
+// or of the fiscal year to date, in dollars.
+type StatementLine struct {
+	StatementDate string  `json:"statement_date"`
+	FiscalYear    string  `json:"fiscal_year"`
+	Period        string  `json:"period"`
+	Receipts      float64 `json:"receipts"`
+	Outlays       float64 `json:"outlays"`
+	Balance       float64 `json:"deficit_surplus"`
+}
+
+// Statement is the receipts and outlays from Monthly Treasury Statements.
+type Statement struct {
+	Lines []StatementLine `json:"lines"`
+}
+
+// Statement gets the receipts and outlays of the Monthly Treasury Statements matching q.
+func (c *Client) Statement(ctx context.Context, q Query) (Statement, error) {
+
+	var statement Statement
+
+	records, err := query[MTSRecord](ctx, c, mtsSummaryPath, q)
+	if err != nil {
+		return statement, err
+	}
+
+	for _, record := range records {
+		line := StatementLine{StatementDate: record.RecordDate, FiscalYear: record.RecordFiscalYear, Period: record.ClassificationDesc}
+		line.Receipts, err = strconv.ParseFloat(record.CurrentMonthGrossRcptAmt, 64)
+		if err != nil {
+			// Heading lines have no amounts
+			continue
+		}
+		line.Outlays, _ = strconv.ParseFloat(record.CurrentMonthGrossOutlyAmt, 64)
+		line.Balance, _ = strconv.ParseFloat(record.CurrentMonthDfctSurAmt, 64)
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	return statement, nil
+}
+
+// PrintTable prints the lines of each statement in billions of dollars.
+func (s Statement) PrintTable(w io.Writer) {
+	fmt.Fprintln(w, "\nMonthly Treasury Statement receipts and outlays ($ billions):")
+	for i, line := range s.Lines {
+		if i == 0 || line.StatementDate != s.Lines[i-1].StatementDate {
+			fmt.Fprintf(w, "\nStatement of %s (fiscal year %s)\n", line.StatementDate, line.FiscalYear)
+			fmt.Fprintf(w, "  %-14s %12s %12s %16s\n", "Period", "Receipts", "Outlays", "Deficit/Surplus")
+		}
+		fmt.Fprintf(w, "  %-14s %12.1f %12.1f %16.1f\n", line.Period, line.Receipts/1e9, line.Outlays/1e9, line.Balance/1e9)
+	}
+	if len(s.Lines) == 0 {
+		fmt.Fprintln(w, "\nNo records found")
+	}
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the statement columns, in dollars.
+func (s Statement) CSVHeader() []string {
+	return []string{"statement_date", "fiscal_year", "period", "receipts", "outlays", "deficit_surplus"}
+}
+
+// CSVRows returns one row per line.
+func (s Statement) CSVRows() [][]string {
+	var rows [][]string
+	for _, line := range s.Lines {
+		rows = append(rows, []string{line.StatementDate, line.FiscalYear, line.Period,
+			output.FormatFloat(line.Receipts), output.FormatFloat(line.Outlays), output.FormatFloat(line.Balance)})
+	}
+	return rows
+}
@@ -0,0 +1,266 @@
+package treasury
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"polyapi/output"
+	"polyapi/provider"
+	"polyapi/store"
+)
+
+// Provider gets the latest Treasury rates and spreads, and keeps them and the daily
+// yield curves.
+type Provider struct {
+	Client *Client
+	Store  *store.Store
+}
+
+// NewProvider returns a Treasury rates provider that stores the rates and yield curves in s.
+func NewProvider(client *Client, s *store.Store) *Provider {
+	return &Provider{Client: client, Store: s}
+}
+
+func (p *Provider) Name() string { return "treasury" }
+
+func (p *Provider) Description() string {
+	return "average U.S. Treasury security rates and spreads (treasury history SECURITY, curve, debt, exchange or mts [--from] [--to] [--filter] [--sort])"
+}
+
+func (p *Provider) RequiredConfig() []string { return nil }
+
+// Fetch returns the latest rates without arguments. The history subcommand shows a
+// security's monthly rates over a date range, by default the last year, and the curve
+// subcommand the latest yield curve. The debt, exchange and mts subcommands get the
+// Debt to the Penny, rates of exchange and Monthly Treasury Statement datasets.
+func (p *Provider) Fetch(ctx context.Context, args []string) (output.Result, error) {
+
+	if len(args) == 0 {
+		return p.Rates(ctx)
+	}
+
+	switch args[0] {
+	case "history":
+		now := time.Now()
+		flags := flag.NewFlagSet("treasury history", flag.ContinueOnError)
+		from := flags.String("from", now.AddDate(-1, 0, 0).Format("2006-01-02"), "first record date (YYYY-MM-DD)")
+		to := flags.String("to", now.Format("2006-01-02"), "last record date (YYYY-MM-DD)")
+		words, err := provider.ParseInterspersed(ctx, flags, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			return nil, provider.Usagef(`usage: treasury history SECURITY [--from YYYY-MM-DD] [--to YYYY-MM-DD], e.g. treasury history "Treasury Bills"`)
+		}
+		for _, date := range []string{*from, *to} {
+			if _, err := time.Parse("2006-01-02", date); err != nil {
+				return nil, provider.Usagef("invalid date %q (use YYYY-MM-DD)", date)
+			}
+		}
+		return p.Client.History(ctx, strings.Join(words, " "), *from, *to)
+	case "curve":
+		if len(args) > 1 {
+			return nil, provider.Usagef("usage: treasury curve")
+		}
+		return p.YieldCurve(ctx)
+	case "debt":
+		q, err := parseQuery(ctx, args, 30, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.Client.Debt(ctx, q)
+	case "exchange":
+		// Rates are published quarterly, so the last 92 days has the latest quarter
+		var country, currency string
+		q, err := parseQuery(ctx, args, 92, func(flags *flag.FlagSet) {
+			flags.StringVar(&country, "country", "", "only this country, e.g. Canada")
+			flags.StringVar(&currency, "currency", "", "only this currency, e.g. Euro")
+		})
+		if err != nil {
+			return nil, err
+		}
+		if country != "" {
+			q.Filters = append(q.Filters, "country:eq:"+country)
+		}
+		if currency != "" {
+			q.Filters = append(q.Filters, "currency:eq:"+currency)
+		}
+		return p.Client.ExchangeRates(ctx, q)
+	case "mts":
+		// Statements are published about two weeks after the month ends
+		q, err := parseQuery(ctx, args, 45, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.Client.Statement(ctx, q)
+	default:
+		return nil, provider.Usagef("unknown treasury subcommand: %s (use history, curve, debt, exchange or mts)", args[0])
+	}
+}
+
+// parseQuery parses the filter and sort flags of a dataset subcommand. Records are
+// from the last days by default, newest first, and extra adds the subcommand's own flags.
+func parseQuery(ctx context.Context, args []string, days int, extra func(flags *flag.FlagSet)) (Query, error) {
+
+	now := time.Now()
+	q := Query{Sort: "-record_date"}
+
+	flags := flag.NewFlagSet("treasury "+args[0], flag.ContinueOnError)
+	from := flags.String("from", now.AddDate(0, 0, -days).Format("2006-01-02"), "first record date (YYYY-MM-DD)")
+	to := flags.String("to", "", "last record date (YYYY-MM-DD)")
+	flags.StringVar(&q.Sort, "sort", q.Sort, "fields to sort by, each prefixed with - to sort descending")
+	flags.IntVar(&q.Limit, "limit", 0, "maximum number of records")
+	flags.Func("filter", "fiscal data filter FIELD:OPERATOR:VALUE, e.g. record_date:gte:2024-01-01 (may be repeated)", func(filter string) error {
+		if err := CheckFilter(filter); err != nil {
+			return err
+		}
+		q.Filters = append(q.Filters, filter)
+		return nil
+	})
+	if extra != nil {
+		extra(flags)
+	}
+	if err := provider.ParseFlags(ctx, flags, args[1:]); err != nil {
+		return q, err
+	}
+	if flags.NArg() > 0 {
+		return q, provider.Usagef("unexpected argument: %s", flags.Arg(0))
+	}
+
+	for _, date := range []string{*from, *to} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			return q, provider.Usagef("invalid date %q (use YYYY-MM-DD)", date)
+		}
+	}
+	if *from != "" {
+		q.Filters = append(q.Filters, "record_date:gte:"+*from)
+	}
+	if *to != "" {
+		q.Filters = append(q.Filters, "record_date:lte:"+*to)
+	}
+
+	return q, nil
+}
+
+// Rates gets the latest average interest rates and saves them, so the metrics can
+// publish them.
+func (p *Provider) Rates(ctx context.Context) (Rates, error) {
+
+	rates, err := p.Client.Rates(ctx)
+	if err != nil {
+		return rates, err
+	}
+
+	stored := store.TreasuryRates{RecordDate: rates.RecordDate}
+	for _, security := range rates.Securities {
+		stored.Securities = append(stored.Securities, store.SecurityRate{Type: security.Type, Security: security.Security, Rate: security.Rate})
+	}
+	if err := p.Store.SaveTreasuryRates(stored); err != nil {
+		return rates, err
+	}
+
+	return rates, nil
+}
+
+// StoredRates returns the latest stored average interest rates with their spreads,
+// without fetching new ones, or false if none are stored.
+func StoredRates(s *store.Store) (Rates, bool, error) {
+
+	stored, ok, err := s.LatestTreasuryRates()
+	if err != nil || !ok {
+		return Rates{}, false, err
+	}
+
+	var securities []SecurityRate
+	for _, security := range stored.Securities {
+		securities = append(securities, SecurityRate{Type: security.Type, Security: security.Security, Rate: security.Rate})
+	}
+	return newRates(stored.RecordDate, securities), true, nil
+}
+
+// YieldCurve fetches the yield curves since the latest stored one, saves them and compares
+// the latest with a week, a month and a year earlier. If the Treasury can't be reached
+// the stored curves are used.
+func (p *Provider) YieldCurve(ctx context.Context) (YieldCurve, error) {
+
+	updateErr := p.update(ctx)
+
+	curve, ok, err := StoredYieldCurve(p.Store)
+	if err != nil {
+		return YieldCurve{}, err
+	}
+	if !ok {
+		if updateErr != nil {
+			return YieldCurve{}, updateErr
+		}
+		return YieldCurve{}, fmt.Errorf("no yield curve data available")
+	}
+	if updateErr != nil {
+		log.Printf("Error updating the yield curve, using the curve stored for %s: %v", curve.Date, updateErr)
+	}
+
+	return curve, nil
+}
+
+// StoredYieldCurve compares the latest stored yield curve with a week, a month and a
+// year earlier without fetching new curves, or returns false if none are stored.
+func StoredYieldCurve(s *store.Store) (YieldCurve, bool, error) {
+
+	latestDate, err := s.LatestYieldCurveDate()
+	if err != nil || latestDate == "" {
+		return YieldCurve{}, false, err
+	}
+
+	date, err := time.Parse("2006-01-02", latestDate)
+	if err != nil {
+		return YieldCurve{}, false, err
+	}
+
+	var curves [4]*store.YieldCurve
+	for i, target := range []time.Time{date, date.AddDate(0, 0, -7), date.AddDate(0, -1, 0), date.AddDate(-1, 0, 0)} {
+		curve, ok, err := s.YieldCurveOn(target.Format("2006-01-02"))
+		if err != nil {
+			return YieldCurve{}, false, err
+		}
+		if ok {
+			curves[i] = &curve
+		}
+	}
+
+	return newYieldCurve(*curves[0], curves[1], curves[2], curves[3]), true, nil
+}
+
+// update fetches and saves the yield curves of each year from the latest stored curve's
+// to this year. Without stored curves last year is fetched too, for the year ago comparison.
+func (p *Provider) update(ctx context.Context) error {
+
+	currentYear := time.Now().Year()
+	startYear := currentYear - 1
+
+	latest, err := p.Store.LatestYieldCurveDate()
+	if err != nil {
+		return err
+	}
+	if latest != "" {
+		if startYear, err = strconv.Atoi(latest[:4]); err != nil {
+			return fmt.Errorf("invalid stored yield curve date %q: %w", latest, err)
+		}
+	}
+
+	for year := startYear; year <= currentYear; year++ {
+		curves, err := p.Client.YieldCurves(ctx, year)
+		if err != nil {
+			return err
+		}
+		if err := p.Store.SaveYieldCurves(curves); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package treasury
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Query filters and sorts the records of a fiscal data dataset.
+type Query struct {
+	// Filters are fiscal data filters such as "record_date:gte:2024-01-01"
+	// or "country:in:(Canada,Mexico)".
+	Filters []string
+	// Sort is a comma separated list of fields, each prefixed with - to sort descending.
+	Sort string
+	// Limit is the maximum number of records returned, or 0 for all of them.
+	Limit int
+}
+
+// filterOperators are the comparison operators of fiscal data filters.
+var filterOperators = map[string]bool{"eq": true, "lt": true, "lte": true, "gt": true, "gte": true, "in": true}
+
+// CheckFilter checks a filter is given as FIELD:OPERATOR:VALUE, e.g. "country:eq:Canada".
+func CheckFilter(filter string) error {
+	field, rest, _ := strings.Cut(filter, ":")
+	operator, value, _ := strings.Cut(rest, ":")
+	if field == "" || value == "" || !filterOperators[operator] {
+		return fmt.Errorf("invalid filter %q (use FIELD:OPERATOR:VALUE with eq, lt, lte, gt, gte or in)", filter)
+	}
+	return nil
+}
+
+// params returns the query's filter and sort parameters.
+func (q Query) params() url.Values {
+	params := url.Values{}
+	if len(q.Filters) > 0 {
+		params.Set("filter", strings.Join(q.Filters, ","))
+	}
+	if q.Sort != "" {
+		params.Set("sort", q.Sort)
+	}
+	return params
+}
+
+// query gets the records of the dataset at path matching q, from every page up to its limit.
+func query[T any](ctx context.Context, c *Client, path string, q Query) ([]T, error) {
+
+	var records []T
+	err := c.pages(ctx, path, q.params(), func(data json.RawMessage) (bool, error) {
+		var page []T
+		if err := json.Unmarshal(data, &page); err != nil {
+			return false, err
+		}
+		records = append(records, page...)
+		if q.Limit > 0 && len(records) >= q.Limit {
+			records = records[:q.Limit]
+			return false, nil
+		}
+		return len(page) > 0, nil
+	})
+
+	return records, err
+}
@@ -0,0 +1,108 @@
+package treasury
+
+import (
+	"fmt"
+	"io"
+
+	"polyapi/output"
+)
+
+// SecurityRate is the average interest rate of a security in a month, e.g. the
+// marketable Treasury Inflation-Protected Securities (TIPS).
+type SecurityRate struct {
+	Type     string  `json:"type"`
+	Security string  `json:"security"`
+	Rate     float64 `json:"rate"`
+}
+
+// Rates are the latest average interest rates on every Treasury security, with
+// the spreads between bills, notes and bonds.
+type Rates struct {
+	RecordDate     string         `json:"record_date"`
+	Securities     []SecurityRate `json:"securities"`
+	Bills          float64        `json:"bills"`
+	Notes          float64        `json:"notes"`
+	Bonds          float64        `json:"bonds"`
+	BondBillSpread float64        `json:"bond_bill_spread"`
+	NoteBillSpread float64        `json:"note_bill_spread"`
+	BondNoteSpread float64        `json:"bond_note_spread"`
+}
+
+// PrintTable prints the rates grouped by security type, followed by the spreads.
+func (r Rates) PrintTable(w io.Writer) {
+	fmt.Fprintf(w, "\nLatest U.S. Treasury Avg Interest Rates (%s):\n", r.RecordDate)
+	for i, security := range r.Securities {
+		if i == 0 || security.Type != r.Securities[i-1].Type {
+			fmt.Fprintf(w, "\n%s:\n", security.Type)
+		}
+		fmt.Fprintf(w, "  %-50s %.3f\n", security.Security, security.Rate)
+	}
+	fmt.Fprintf(w, "\nSpread (Bond to Bill): %.2f\n", r.BondBillSpread)
+	fmt.Fprintf(w, "Spread (Note to Bill): %.2f\n", r.NoteBillSpread)
+	fmt.Fprintf(w, "Spread (Bond to Note): %.2f\n", r.BondNoteSpread)
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the security rate columns.
+func (r Rates) CSVHeader() []string {
+	return []string{"record_date", "security_type", "security", "avg_interest_rate"}
+}
+
+// CSVRows returns one row per security.
+func (r Rates) CSVRows() [][]string {
+	var rows [][]string
+	for _, security := range r.Securities {
+		rows = append(rows, []string{r.RecordDate, security.Type, security.Security, output.FormatFloat(security.Rate)})
+	}
+	return rows
+}
+
+// MonthlyRate is the average interest rate of a security in a month, with the
+// change from the previous month in percentage points.
+type MonthlyRate struct {
+	Date   string   `json:"date"`
+	Rate   float64  `json:"rate"`
+	Change *float64 `json:"change,omitempty"`
+}
+
+// RateHistory is the monthly average interest rate of a security over a date range.
+type RateHistory struct {
+	Security  string        `json:"security"`
+	Type      string        `json:"type"`
+	StartDate string        `json:"start_date"`
+	EndDate   string        `json:"end_date"`
+	Rates     []MonthlyRate `json:"rates"`
+}
+
+// PrintTable prints each month's rate with its change from the previous month.
+func (h RateHistory) PrintTable(w io.Writer) {
+	fmt.Fprintf(w, "\n%s (%s) Avg Interest Rates from %s to %s:\n\n", h.Security, h.Type, h.StartDate, h.EndDate)
+	fmt.Fprintf(w, "%-12s %8s %8s\n", "Date", "Rate", "Change")
+	for _, month := range h.Rates {
+		change := ""
+		if month.Change != nil {
+			change = fmt.Sprintf("%+.3f", *month.Change)
+		}
+		fmt.Fprintf(w, "%-12s %8.3f %8s\n", month.Date, month.Rate, change)
+	}
+
+	values := make([]float64, len(h.Rates))
+	for i, month := range h.Rates {
+		values[i] = month.Rate
+	}
+	fmt.Fprintf(w, "\n%s\n\n", output.Sparkline(values, 60))
+}
+
+// CSVHeader returns the history columns.
+func (h RateHistory) CSVHeader() []string {
+	return []string{"date", "security", "rate", "change"}
+}
+
+// CSVRows returns one row per month.
+func (h RateHistory) CSVRows() [][]string {
+	var rows [][]string
+	for _, month := range h.Rates {
+		rows = append(rows, []string{month.Date, h.Security, output.FormatFloat(month.Rate), output.FormatOptional(month.Change)})
+	}
+	return rows
+}
@@ -0,0 +1,229 @@
+// Package treasury gets average interest rates on U.S. Treasury securities
+// from the fiscaldata.treasury.gov API, and the daily par yield curve from
+// home.treasury.gov. No API key is required.
+package treasury
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"polyapi/httpx"
+)
+
+type TreasuryData struct {
+	RecordDate         string `json:"record_date"`
+	SecurityTypeDesc   string `json:"security_type_desc"`
+	SecurityDesc       string `json:"security_desc"`
+	AvgInterestRateAmt string `json:"avg_interest_rate_amt"`
+	SrcLineNbr         string `json:"src_line_nbr"`
+}
+
+// Page is a page of a fiscal data response. Links.Next is the page[number] and
+// page[size] query of the next page, or nil on the last page.
+type Page struct {
+	Data json.RawMessage `json:"data"`
+	Meta struct {
+		TotalCount int `json:"total-count"`
+		TotalPages int `json:"total-pages"`
+	} `json:"meta"`
+	Links struct {
+		Next *string `json:"next"`
+	} `json:"links"`
+}
+
+// DefaultBaseURL is the base URL of the Treasury fiscal data API.
+const DefaultBaseURL = "https://api.fiscaldata.treasury.gov"
+
+// PageSize is the number of records requested per page of a fiscal data response.
+const PageSize = 100
+
+// avgInterestRatesPath is the path of the average interest rates dataset.
+const avgInterestRatesPath = "/services/api/fiscal_service/v2/accounting/od/avg_interest_rates"
+
+// Client calls the fiscaldata.treasury.gov API and the home.treasury.gov interest rate data.
+type Client struct {
+	HTTPClient        *http.Client
+	BaseURL           string
+	YieldCurveBaseURL string
+}
+
+// NewClient returns a Treasury client.
+func NewClient() *Client {
+	return &Client{HTTPClient: httpx.DefaultClient, BaseURL: DefaultBaseURL, YieldCurveBaseURL: DefaultYieldCurveBaseURL}
+}
+
+// pages requests a fiscal data dataset with params and calls each with the data of each
+// page, following links.next until the last page or until each returns false.
+func (c *Client) pages(ctx context.Context, path string, params url.Values, each func(data json.RawMessage) (bool, error)) error {
+
+	params.Set("format", "json")
+	query := params.Encode()
+	next := fmt.Sprintf("&page%%5Bnumber%%5D=1&page%%5Bsize%%5D=%d", PageSize)
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+path+"?"+query+next, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+		if err := httpx.CheckResponse(resp); err != nil {
+			return err
+		}
+
+		var page Page
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		more, err := each(page.Data)
+		if err != nil {
+			return err
+		}
+		if !more || page.Links.Next == nil || *page.Links.Next == "" {
+			return nil
+		}
+		next = *page.Links.Next
+	}
+}
+
+// averageRates gets the average interest rate records matching params, following
+// the pagination until each returns false for a page's records.
+func (c *Client) averageRates(ctx context.Context, params url.Values, each func(records []TreasuryData) bool) error {
+	return c.pages(ctx, avgInterestRatesPath, params, func(data json.RawMessage) (bool, error) {
+		var records []TreasuryData
+		if err := json.Unmarshal(data, &records); err != nil {
+			return false, err
+		}
+		return each(records) && len(records) > 0, nil
+	})
+}
+
+// Rates gets the average interest rates of every marketable and non-marketable security
+// in the latest month, and calculates the spreads between bills, notes and bonds.
+func (c *Client) Rates(ctx context.Context) (Rates, error) {
+
+	var rates Rates
+
+	// The latest month comes first, and may span pages
+	var latest []TreasuryData
+	err := c.averageRates(ctx, url.Values{"sort": {"-record_date"}}, func(records []TreasuryData) bool {
+		for _, record := range records {
+			if rates.RecordDate == "" {
+				rates.RecordDate = record.RecordDate
+			}
+			if record.RecordDate != rates.RecordDate {
+				return false
+			}
+			latest = append(latest, record)
+		}
+		return true
+	})
+	if err != nil {
+		return rates, err
+	}
+
+	if len(latest) == 0 {
+		return rates, fmt.Errorf("no treasury data available")
+	}
+
+	sort.SliceStable(latest, func(i, j int) bool { return lineNumber(latest[i]) < lineNumber(latest[j]) })
+
+	var securities []SecurityRate
+	for _, record := range latest {
+		rate, err := strconv.ParseFloat(record.AvgInterestRateAmt, 64)
+		if err != nil {
+			// Securities without a rate that month are reported as "null"
+			continue
+		}
+		securities = append(securities, SecurityRate{Type: record.SecurityTypeDesc, Security: record.SecurityDesc, Rate: rate})
+	}
+
+	return newRates(rates.RecordDate, securities), nil
+}
+
+// newRates returns a month's rates with the spreads between bills, notes and bonds.
+func newRates(recordDate string, securities []SecurityRate) Rates {
+
+	rates := Rates{RecordDate: recordDate, Securities: securities}
+	for _, security := range securities {
+		switch security.Security {
+		case "Treasury Bills":
+			rates.Bills = security.Rate
+		case "Treasury Notes":
+			rates.Notes = security.Rate
+		case "Treasury Bonds":
+			rates.Bonds = security.Rate
+		}
+	}
+
+	// Rates have 3 decimals, so round away the floating point error
+	rates.BondBillSpread = math.Round((rates.Bonds-rates.Bills)*1000) / 1000
+	rates.NoteBillSpread = math.Round((rates.Notes-rates.Bills)*1000) / 1000
+	rates.BondNoteSpread = math.Round((rates.Bonds-rates.Notes)*1000) / 1000
+
+	return rates
+}
+
+// lineNumber returns the line of a record in the Treasury's report, which orders the securities.
+func lineNumber(record TreasuryData) int {
+	n, _ := strconv.Atoi(record.SrcLineNbr)
+	return n
+}
+
+// History gets the monthly average interest rate of a security, e.g. "Treasury Bills",
+// from startDate to endDate, oldest first, with the change from each previous month.
+func (c *Client) History(ctx context.Context, security, startDate, endDate string) (RateHistory, error) {
+
+	history := RateHistory{Security: security, StartDate: startDate, EndDate: endDate}
+
+	params := url.Values{
+		"filter": {strings.Join([]string{
+			"security_desc:eq:" + security,
+			"record_date:gte:" + startDate,
+			"record_date:lte:" + endDate,
+		}, ",")},
+		"sort": {"record_date"},
+	}
+
+	var previous *float64
+	err := c.averageRates(ctx, params, func(records []TreasuryData) bool {
+		for _, record := range records {
+			rate, err := strconv.ParseFloat(record.AvgInterestRateAmt, 64)
+			if err != nil {
+				continue
+			}
+			history.Type = record.SecurityTypeDesc
+
+			month := MonthlyRate{Date: record.RecordDate, Rate: rate}
+			if previous != nil {
+				// Rates have 3 decimals, so round away the floating point error
+				change := math.Round((rate-*previous)*1000) / 1000
+				month.Change = &change
+			}
+			previous = &rate
+			history.Rates = append(history.Rates, month)
+		}
+		return true
+	})
+	if err != nil {
+		return history, err
+	}
+
+	if len(history.Rates) == 0 {
+		return history, fmt.Errorf("no average interest rates for %q from %s to %s (run `polyapi treasury` for the security names)", security, startDate, endDate)
+	}
+
+	return history, nil
+}
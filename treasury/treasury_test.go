@@ -0,0 +1,274 @@
+package treasury
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"polyapi/provider"
+	"polyapi/store"
+)
+
+func TestRates(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/api/fiscal_service/v2/accounting/od/avg_interest_rates" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		query := r.URL.Query()
+		if got := query.Get("sort"); got != "-record_date" {
+			t.Errorf("sort = %q, want -record_date", got)
+		}
+		pages = append(pages, query.Get("page[number]"))
+		switch query.Get("page[number]") {
+		case "1":
+			http.ServeFile(w, r, filepath.Join("testdata", "avg_interest_rates.json"))
+		case "2":
+			http.ServeFile(w, r, filepath.Join("testdata", "avg_interest_rates_2.json"))
+		default:
+			t.Errorf("unexpected page: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	client := NewClient()
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := NewProvider(client, s)
+
+	rates, err := p.Rates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The next page is followed until the latest month ends
+	if strings.Join(pages, ",") != "1,2" {
+		t.Errorf("pages = %v, want 1,2", pages)
+	}
+	if rates.RecordDate != "2024-07-31" {
+		t.Errorf("RecordDate = %s, want 2024-07-31", rates.RecordDate)
+	}
+
+	// Every security of the latest month is listed in the report's order, skipping null rates
+	var securities []string
+	for _, security := range rates.Securities {
+		securities = append(securities, security.Security)
+	}
+	want := "Treasury Bills,Treasury Notes,Treasury Bonds,Treasury Inflation-Protected Securities (TIPS),Treasury Floating Rate Notes (FRN)," +
+		"United States Savings Securities,Government Account Series"
+	if strings.Join(securities, ",") != want {
+		t.Errorf("securities = %v", securities)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"Bills", rates.Bills, 5.346},
+		{"Notes", rates.Notes, 2.818},
+		{"Bonds", rates.Bonds, 3.196},
+		{"BondBillSpread", rates.BondBillSpread, -2.15},
+		{"NoteBillSpread", rates.NoteBillSpread, -2.528},
+		{"BondNoteSpread", rates.BondNoteSpread, 0.378},
+	} {
+		if math.Abs(tc.got-tc.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+
+	// The rates are saved, in the same order and with the same spreads
+	stored, ok, err := StoredRates(s)
+	if err != nil || !ok {
+		t.Fatalf("StoredRates() = %v, %v", ok, err)
+	}
+	if !reflect.DeepEqual(stored, rates) {
+		t.Errorf("stored rates = %+v, want %+v", stored, rates)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("filter") != "security_desc:eq:Treasury Bills,record_date:gte:2024-04-01,record_date:lte:2024-07-31" || query.Get("sort") != "record_date" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", "avg_interest_rates_bills.json"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := NewProvider(client, nil)
+
+	result, err := p.Fetch(context.Background(), []string{"history", "Treasury", "Bills", "--from", "2024-04-01", "--to=2024-07-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := result.(RateHistory)
+
+	if history.Type != "Marketable" || len(history.Rates) != 4 || history.Rates[0].Change != nil {
+		t.Fatalf("history = %+v", history)
+	}
+	// Month over month changes
+	if last := history.Rates[3]; last.Date != "2024-07-31" || last.Change == nil || *last.Change != -0.013 {
+		t.Errorf("last month = %+v", last)
+	}
+
+	var usage *provider.UsageError
+	if _, err := p.Fetch(context.Background(), []string{"history", "--from", "2024-04-01"}); !errors.As(err, &usage) {
+		t.Errorf("history without a security: %v, want a usage error", err)
+	}
+}
+
+func TestYieldCurve(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !strings.HasPrefix(r.URL.Path, "/resource-center/data-chart-center/interest-rates/daily-treasury-rates.csv/") ||
+			r.URL.Query().Get("type") != "daily_treasury_yield_curve" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", "yield_curve.csv"))
+	}))
+	defer server.Close()
+
+	s, err := store.Open(filepath.Join(t.TempDir(), "polyapi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	client := NewClient()
+	client.HTTPClient = server.Client()
+	client.YieldCurveBaseURL = server.URL
+	p := NewProvider(client, s)
+
+	curve, err := p.YieldCurve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Last year is fetched too for the year ago comparison
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+	if curve.Date != "2024-08-23" || curve.WeekAgoDate != "2024-08-16" || curve.MonthAgoDate != "2024-07-23" || curve.YearAgoDate != "2023-08-23" {
+		t.Errorf("dates = %s, %s, %s, %s", curve.Date, curve.WeekAgoDate, curve.MonthAgoDate, curve.YearAgoDate)
+	}
+
+	// Sorted by maturity, skipping the empty 1.5 month yields
+	if len(curve.Yields) != 13 || curve.Yields[0].Name != "1M" || curve.Yields[5].Name != "1Y" || curve.Yields[12].Name != "30Y" {
+		t.Fatalf("yields = %+v", curve.Yields)
+	}
+	if tenYear := curve.Yields[10]; tenYear.Name != "10Y" || tenYear.Value != 3.81 || tenYear.YearAgo == nil || *tenYear.YearAgo != 4.19 {
+		t.Errorf("10 year = %+v", tenYear)
+	}
+
+	if len(curve.Spreads) != 2 || !curve.Inverted {
+		t.Fatalf("spreads = %+v, inverted %v", curve.Spreads, curve.Inverted)
+	}
+	if s := curve.Spreads[0]; s.Name != "2s10s" || s.Value != -0.11 || *s.WeekAgo != -0.16 || *s.YearAgo != -0.79 {
+		t.Errorf("2s10s = %+v", s)
+	}
+	if s := curve.Spreads[1]; s.Name != "3m10y" || s.Value != -1.54 || *s.MonthAgo != -1.18 {
+		t.Errorf("3m10y = %+v", s)
+	}
+
+	// The stored curves are used when the Treasury can't be reached
+	server.Close()
+	if curve, err = p.YieldCurve(context.Background()); err != nil || curve.Date != "2024-08-23" {
+		t.Errorf("offline curve = %+v, %v", curve, err)
+	}
+}
+
+func TestParseMaturity(t *testing.T) {
+	for name, want := range map[string]string{"1 Mo": "1M", "1.5 Month": "1.5M", "6 Wk": "6W", "10 Yr": "10Y"} {
+		if got, err := parseMaturity(name); err != nil || got != want {
+			t.Errorf("parseMaturity(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := parseMaturity("Date"); err == nil {
+		t.Error("expected an error for an unknown maturity")
+	}
+}
+
+func TestDatasets(t *testing.T) {
+	requests := map[string]url.Values{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path] = r.URL.Query()
+		switch r.URL.Path {
+		case debtToPennyPath:
+			if r.URL.Query().Get("page[number]") != "1" {
+				t.Errorf("the limit should stop at the first page: %s", r.URL)
+			}
+			http.ServeFile(w, r, filepath.Join("testdata", "debt_to_penny.json"))
+		case ratesOfExchangePath:
+			http.ServeFile(w, r, filepath.Join("testdata", "rates_of_exchange.json"))
+		case mtsSummaryPath:
+			http.ServeFile(w, r, filepath.Join("testdata", "mts_table_1.json"))
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	p := NewProvider(client, nil)
+	ctx := context.Background()
+
+	result, err := p.Fetch(ctx, []string{"debt", "--from", "2024-08-01", "--to", "2024-08-23", "--limit", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	debt := result.(Debt)
+	if query := requests[debtToPennyPath]; query.Get("filter") != "record_date:gte:2024-08-01,record_date:lte:2024-08-23" || query.Get("sort") != "-record_date" {
+		t.Errorf("debt query = %v", query)
+	}
+	if len(debt.Days) != 2 || debt.Days[0].Date != "2024-08-23" || debt.Days[0].Total != 35381935609453.08 {
+		t.Errorf("debt = %+v", debt)
+	}
+
+	result, err = p.Fetch(ctx, []string{"exchange", "--country", "Canada", "--from=", "--sort", "record_date"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query := requests[ratesOfExchangePath]; query.Get("filter") != "country:eq:Canada" || query.Get("sort") != "record_date" {
+		t.Errorf("exchange query = %v", query)
+	}
+	if rates := result.(ExchangeRates); len(rates.Rates) != 2 || rates.Rates[0].Rate != 1.368 || rates.Rates[0].Currency != "Dollar" {
+		t.Errorf("exchange rates = %+v", rates)
+	}
+
+	result, err = p.Fetch(ctx, []string{"mts", "--filter", "record_fiscal_year:eq:2024"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter := requests[mtsSummaryPath].Get("filter"); !strings.HasPrefix(filter, "record_fiscal_year:eq:2024,record_date:gte:") {
+		t.Errorf("mts filter = %q", filter)
+	}
+	// Heading lines without amounts are skipped
+	statement := result.(Statement)
+	if len(statement.Lines) != 3 || statement.Lines[1].Period != "July" || statement.Lines[1].Balance != -243705361070.89 {
+		t.Errorf("statement = %+v", statement)
+	}
+
+	if _, err := p.Fetch(ctx, []string{"debt", "--filter", "record_date>2024"}); err == nil {
+		t.Error("expected an error for an invalid filter")
+	}
+}
@@ -0,0 +1,170 @@
+package treasury
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"polyapi/output"
+	"polyapi/store"
+)
+
+// CurveRate is a yield or spread on the curve's date, with its values a week,
+// a month and a year earlier when they are stored.
+type CurveRate struct {
+	Name     string   `json:"name"`
+	Value    float64  `json:"value"`
+	WeekAgo  *float64 `json:"week_ago,omitempty"`
+	MonthAgo *float64 `json:"month_ago,omitempty"`
+	YearAgo  *float64 `json:"year_ago,omitempty"`
+}
+
+// YieldCurve is the daily Treasury par yield curve from 1 month to 30 years, with the
+// 2s10s (10 year minus 2 year) and 3m10y (10 year minus 3 month) spreads. The curve is
+// inverted when either spread is negative.
+type YieldCurve struct {
+	Date         string      `json:"date"`
+	WeekAgoDate  string      `json:"week_ago_date,omitempty"`
+	MonthAgoDate string      `json:"month_ago_date,omitempty"`
+	YearAgoDate  string      `json:"year_ago_date,omitempty"`
+	Yields       []CurveRate `json:"yields"`
+	Spreads      []CurveRate `json:"spreads"`
+	Inverted     bool        `json:"inverted"`
+}
+
+// spreads are the spreads calculated from each curve, as the long maturity minus the short one.
+var spreads = []struct{ name, long, short string }{
+	{"2s10s", "10Y", "2Y"},
+	{"3m10y", "10Y", "3M"},
+}
+
+// newYieldCurve builds the latest curve compared with the curves a week, a month and a
+// year earlier. Earlier curves that aren't stored are nil.
+func newYieldCurve(latest store.YieldCurve, weekAgo, monthAgo, yearAgo *store.YieldCurve) YieldCurve {
+
+	curve := YieldCurve{Date: latest.Date}
+	if weekAgo != nil {
+		curve.WeekAgoDate = weekAgo.Date
+	}
+	if monthAgo != nil {
+		curve.MonthAgoDate = monthAgo.Date
+	}
+	if yearAgo != nil {
+		curve.YearAgoDate = yearAgo.Date
+	}
+
+	sortYields(latest.Yields)
+	for _, yield := range latest.Yields {
+		curve.Yields = append(curve.Yields, CurveRate{
+			Name:     yield.Maturity,
+			Value:    yield.Yield,
+			WeekAgo:  yieldOf(weekAgo, yield.Maturity),
+			MonthAgo: yieldOf(monthAgo, yield.Maturity),
+			YearAgo:  yieldOf(yearAgo, yield.Maturity),
+		})
+	}
+
+	for _, s := range spreads {
+		value := spreadOf(&latest, s.long, s.short)
+		if value == nil {
+			continue
+		}
+		curve.Spreads = append(curve.Spreads, CurveRate{
+			Name:     s.name,
+			Value:    *value,
+			WeekAgo:  spreadOf(weekAgo, s.long, s.short),
+			MonthAgo: spreadOf(monthAgo, s.long, s.short),
+			YearAgo:  spreadOf(yearAgo, s.long, s.short),
+		})
+		if *value < 0 {
+			curve.Inverted = true
+		}
+	}
+
+	return curve
+}
+
+// yieldOf returns the yield of a maturity on a curve, or nil if the curve or the maturity is missing.
+func yieldOf(curve *store.YieldCurve, maturity string) *float64 {
+	if curve == nil {
+		return nil
+	}
+	for _, yield := range curve.Yields {
+		if yield.Maturity == maturity {
+			value := yield.Yield
+			return &value
+		}
+	}
+	return nil
+}
+
+// spreadOf returns the long maturity's yield minus the short one's, or nil if either is missing.
+func spreadOf(curve *store.YieldCurve, long, short string) *float64 {
+	longYield, shortYield := yieldOf(curve, long), yieldOf(curve, short)
+	if longYield == nil || shortYield == nil {
+		return nil
+	}
+	// Yields have 2 decimals, so round away the floating point error
+	spread := math.Round((*longYield-*shortYield)*100) / 100
+	return &spread
+}
+
+// PrintTable prints each yield and spread with its change in basis points from a
+// week, a month and a year earlier, followed by whether the curve is inverted.
+func (c YieldCurve) PrintTable(w io.Writer) {
+
+	fmt.Fprintf(w, "\nU.S. Treasury Par Yield Curve on %s:\n\n", c.Date)
+
+	fmt.Fprintf(w, "%-8s %7s %10s %10s %10s\n", "", "Yield", "1 week", "1 month", "1 year")
+	fmt.Fprintf(w, "%-8s %7s %10s %10s %10s\n", "", "", dateOrNA(c.WeekAgoDate), dateOrNA(c.MonthAgoDate), dateOrNA(c.YearAgoDate))
+	for _, rate := range c.Yields {
+		printCurveRate(w, rate)
+	}
+	fmt.Fprintln(w)
+	for _, rate := range c.Spreads {
+		printCurveRate(w, rate)
+	}
+	fmt.Fprintln(w, "\nChanges are in basis points.")
+
+	if c.Inverted {
+		fmt.Fprintln(w, "\n*** The yield curve is INVERTED ***")
+	} else {
+		fmt.Fprintln(w, "\nThe yield curve is not inverted.")
+	}
+	fmt.Fprintln(w)
+}
+
+func printCurveRate(w io.Writer, rate CurveRate) {
+	fmt.Fprintf(w, "%-8s %7.2f %10s %10s %10s\n", rate.Name, rate.Value,
+		basisPoints(rate.Value, rate.WeekAgo), basisPoints(rate.Value, rate.MonthAgo), basisPoints(rate.Value, rate.YearAgo))
+}
+
+// basisPoints formats the change from an earlier rate in basis points, or "n/a" if it's missing.
+func basisPoints(value float64, earlier *float64) string {
+	if earlier == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.0f", (value-*earlier)*100)
+}
+
+func dateOrNA(date string) string {
+	if date == "" {
+		return "n/a"
+	}
+	return date
+}
+
+// CSVHeader returns the yield curve columns.
+func (c YieldCurve) CSVHeader() []string {
+	return []string{"date", "name", "value", "week_ago", "month_ago", "year_ago", "inverted"}
+}
+
+// CSVRows returns one row per maturity, then one per spread.
+func (c YieldCurve) CSVRows() [][]string {
+	var rows [][]string
+	for _, rate := range append(append([]CurveRate{}, c.Yields...), c.Spreads...) {
+		rows = append(rows, []string{c.Date, rate.Name, output.FormatFloat(rate.Value), output.FormatOptional(rate.WeekAgo),
+			output.FormatOptional(rate.MonthAgo), output.FormatOptional(rate.YearAgo), fmt.Sprint(c.Inverted)})
+	}
+	return rows
+}
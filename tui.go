@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"polyapi/output"
+	"polyapi/provider"
+	"polyapi/store"
+	"polyapi/tui"
+	"polyapi/weather"
+)
+
+// refreshEvery is how often the TUI refreshes the result shown, e.g. live scores.
+// Responses are still cached for each provider's TTL, so slower-changing data such as
+// the Treasury rates isn't fetched that often.
+const refreshEvery = time.Minute
+
+// leagueTitles are the leagues listed in the sports pane, by league key.
+var leagueTitles = []struct{ League, Title string }{
+	{"NFL", "NFL"},
+	{"College", "College Football - All"},
+	{"College25", "College Football - Top 25"},
+	{"MLB", "MLB"},
+	{"EPL", "English Premier League"},
+	{"MLS", "MLS"},
+	{"NHL", "NHL"},
+	{"WNBA", "WNBA"},
+	{"NBA", "NBA"},
+	{"CollegeBB", "NCAA Men's Basketball"},
+}
+
+// runTUI runs the full-screen terminal UI with a pane for the weather, markets,
+// economy, sports and CRM.
+func (a *app) runTUI(ctx context.Context) error {
+	return tui.Run(ctx, []tui.Pane{
+		{Title: "Weather", Entries: a.weatherEntries},
+		{Title: "Markets", Entries: a.marketEntries},
+		{Title: "Economy", Entries: a.economyEntries},
+		{Title: "Sports", Entries: a.sportsEntries},
+		{Title: "CRM", Entries: a.crmEntries},
+	}, refreshEvery)
+}
+
+// asResult returns a typed provider result as an output.Result, or the error.
+func asResult[T output.Result](result T, err error) (output.Result, error) {
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fetch runs a registered provider like its command, e.g. fetch(ctx, "treasury", "debt").
+func fetch(ctx context.Context, name string, args ...string) (output.Result, error) {
+
+	p, ok := provider.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown provider: %s", name)
+	}
+	if err := provider.CheckConfig(p); err != nil {
+		return nil, err
+	}
+
+	return p.Fetch(ctx, args)
+}
+
+// fetchWith returns an action that runs a provider with args followed by the words
+// entered at the action's prompt, e.g. the text of a FRED search and its --add flag.
+func fetchWith(name string, args ...string) func(ctx context.Context, input string) (output.Result, error) {
+	return func(ctx context.Context, input string) (output.Result, error) {
+		return fetch(ctx, name, append(append([]string{}, args...), strings.Fields(input)...)...)
+	}
+}
+
+// weatherEntries lists an entry to look up a new address and the saved addresses.
+func (a *app) weatherEntries() ([]tui.Entry, error) {
+
+	addresses, err := a.store.Addresses()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []tui.Entry{{
+		Label: "New address…",
+		Actions: []tui.Action{{
+			Name:   "look up",
+			Prompt: "Address (e.g. 432 Park Ave, 10022 or 432 Park Ave NY, NY 10022)",
+			Reload: true,
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				address, err := a.weather.SaveAddress(ctx, input)
+				if err != nil {
+					return nil, err
+				}
+				report, _, err := a.weather.Weather(ctx, address)
+				return asResult(report, err)
+			},
+		}},
+	}}
+
+	for _, address := range addresses {
+		label := address.MatchedAddress
+		if address.LastTemperature != "" {
+			label += " ~ " + address.LastTemperature
+		}
+		entries = append(entries, tui.Entry{Label: label, Actions: a.addressActions(address)})
+	}
+
+	return entries, nil
+}
+
+// addressActions are the weather, forecasts, local economy and history of a saved
+// address, and deleting it.
+func (a *app) addressActions(address store.Address) []tui.Action {
+
+	// forecast returns the forecast or the hourly forecast of the address
+	forecast := func(hourly bool) func(ctx context.Context, input string) (output.Result, error) {
+		return func(ctx context.Context, input string) (output.Result, error) {
+			points, err := a.weather.Client.Points(ctx, fmt.Sprintf("%.8f", address.Latitude), fmt.Sprintf("%.8f", address.Longitude))
+			if err != nil {
+				return nil, err
+			}
+			if hourly {
+				periods, err := a.weather.Client.HourlyForecast(ctx, points)
+				return asResult(weather.Report{Hourly: periods}, err)
+			}
+			periods, err := a.weather.Client.Forecast(ctx, points)
+			return asResult(weather.Report{Forecast: periods}, err)
+		}
+	}
+
+	return []tui.Action{
+		{
+			Name:    "weather",
+			Refresh: true,
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				report, _, err := a.weather.Weather(ctx, address)
+				return asResult(report, err)
+			},
+		},
+		{Key: 'f', Name: "forecast", Refresh: true, Run: forecast(false)},
+		{Key: 'h', Name: "hourly forecast", Refresh: true, Run: forecast(true)},
+		{
+			Key:  'e',
+			Name: "local economy",
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				return asResult(a.localIndicators(ctx, address))
+			},
+		},
+		{
+			Key:    't',
+			Name:   "temperature history",
+			Prompt: "Start and end dates (YYYY-MM-DD YYYY-MM-DD, blank for the last 30 days)",
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				from, to, err := parseDateRange(input)
+				if err != nil {
+					return nil, err
+				}
+				return asResult(a.temperatureHistory(address, from, to))
+			},
+		},
+		{
+			Key:    'd',
+			Name:   "delete",
+			Prompt: "Delete " + address.MatchedAddress + "? (y/n)",
+			Reload: true,
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				return deleted(input, "Address", func() (bool, error) { return a.store.DeleteAddress(address.Id) })
+			},
+		},
+	}
+}
+
+// deleted deletes a saved address or ticker symbol if the answer to the prompt is y.
+func deleted(answer, what string, remove func() (bool, error)) (output.Result, error) {
+
+	if !strings.EqualFold(answer, "y") {
+		return tui.Message(what + " not deleted."), nil
+	}
+
+	ok, err := remove()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return tui.Message(what + " not found."), nil
+	}
+	return tui.Message(what + " deleted successfully."), nil
+}
+
+// marketEntries lists an entry to quote a new ticker symbol, the saved ticker symbols
+// and the Treasury datasets.
+func (a *app) marketEntries() ([]tui.Entry, error) {
+
+	tickers, err := a.store.Tickers()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []tui.Entry{{
+		Label: "New ticker symbol…",
+		Actions: []tui.Action{{
+			Name:   "quote",
+			Prompt: "Ticker symbol (e.g. AAPL, GOOG)",
+			Reload: true,
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				return fetch(ctx, "quote", strings.ToUpper(input))
+			},
+		}},
+	}}
+
+	for _, ticker := range tickers {
+		entries = append(entries, tui.Entry{
+			Label:   fmt.Sprintf("%s %s %s", ticker.Ticker, ticker.CompanyName, ticker.LastPrice),
+			Actions: a.tickerActions(ticker),
+		})
+	}
+
+	return append(entries,
+		tui.Entry{Label: "Treasury average interest rates", Actions: []tui.Action{
+			{Name: "rates", Refresh: true, Run: fetchWith("treasury")},
+		}},
+		tui.Entry{Label: "Treasury daily par yield curve", Actions: []tui.Action{
+			{Name: "curve", Refresh: true, Run: fetchWith("treasury", "curve")},
+		}},
+		tui.Entry{Label: "Treasury security history…", Actions: []tui.Action{{
+			Name:   "history",
+			Prompt: "Security [--from YYYY-MM-DD] (e.g. Treasury Bills, Treasury Floating Rate Notes (FRN))",
+			Run:    fetchWith("treasury", "history"),
+		}}},
+		tui.Entry{Label: "Total public debt (Debt to the Penny)", Actions: []tui.Action{
+			{Name: "debt", Refresh: true, Run: fetchWith("treasury", "debt")},
+		}},
+		tui.Entry{Label: "Treasury rates of exchange…", Actions: []tui.Action{{
+			Name:   "exchange rates",
+			Prompt: "Country (e.g. Canada, blank for every country)",
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				if input == "" {
+					return fetch(ctx, "treasury", "exchange")
+				}
+				return fetch(ctx, "treasury", "exchange", "--country", input)
+			},
+		}}},
+		tui.Entry{Label: "Receipts and outlays (Monthly Treasury Statement)", Actions: []tui.Action{
+			{Name: "statement", Refresh: true, Run: fetchWith("treasury", "mts")},
+		}},
+	), nil
+}
+
+// tickerActions are the quote and price history of a saved ticker symbol, and
+// deleting it.
+func (a *app) tickerActions(ticker store.Ticker) []tui.Action {
+	return []tui.Action{
+		{Name: "quote", Refresh: true, Run: fetchWith("quote", ticker.Ticker)},
+		{
+			Key:    'p',
+			Name:   "price history",
+			Prompt: "Start and end dates (YYYY-MM-DD YYYY-MM-DD, blank for the last 30 days)",
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				from, to, err := parseDateRange(input)
+				if err != nil {
+					return nil, err
+				}
+				return asResult(a.priceHistory(ticker, from, to))
+			},
+		},
+		{
+			Key:    'd',
+			Name:   "delete",
+			Prompt: "Delete " + ticker.Ticker + "? (y/n)",
+			Reload: true,
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				return deleted(input, "Ticker symbol", func() (bool, error) { return a.store.DeleteTicker(ticker.Id) })
+			},
+		},
+	}
+}
+
+// economyEntries lists the morning briefing, the BLS and FRED watchlists, the release
+// calendar and the FRED catalog.
+func (a *app) economyEntries() ([]tui.Entry, error) {
+
+	// calendar returns this week's releases, first fetching the series released since
+	// they were last fetched if refresh is set
+	calendar := func(refresh bool) func(ctx context.Context, input string) (output.Result, error) {
+		return func(ctx context.Context, input string) (output.Result, error) {
+			if err := provider.CheckConfig(a.fred); err != nil {
+				return nil, err
+			}
+			calendar, err := a.fred.Calendar(ctx, 7)
+			if err != nil || !refresh || len(calendar.Stale) == 0 {
+				return asResult(calendar, err)
+			}
+			if err := a.refreshStale(ctx, calendar); err != nil {
+				return nil, err
+			}
+			return asResult(a.fred.Calendar(ctx, 7))
+		}
+	}
+
+	return []tui.Entry{
+		{Label: "Morning briefing", Actions: []tui.Action{{
+			Name:    "brief",
+			Refresh: true,
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				leagues, err := parseLeagues(favoriteLeagues())
+				if err != nil {
+					return nil, err
+				}
+				return a.brief(ctx, "", leagues), nil
+			},
+		}}},
+		{Label: "BLS watchlist", Actions: []tui.Action{
+			{Name: "latest values", Refresh: true, Run: fetchWith("bls")},
+		}},
+		{Label: "FRED dashboard", Actions: []tui.Action{
+			{Name: "latest values", Refresh: true, Run: fetchWith("fred")},
+		}},
+		{Label: "Release calendar", Actions: []tui.Action{
+			{Name: "this week", Refresh: true, Run: calendar(false)},
+			{Key: 'u', Name: "update released series", Run: calendar(true)},
+		}},
+		{Label: "Search FRED series…", Actions: []tui.Action{{
+			Name:   "search",
+			Prompt: "Search text [--add N] (e.g. consumer sentiment)",
+			Run:    fetchWith("fred", "search"),
+		}}},
+		{Label: "Browse FRED categories…", Actions: []tui.Action{{
+			Name:   "category",
+			Prompt: "Category ID [--add N] (blank for the top categories)",
+			Run:    fetchWith("fred", "category"),
+		}}},
+		{Label: "FRED series revisions…", Actions: []tui.Action{{
+			Name:   "revisions",
+			Prompt: "Series ID [--date YYYY-MM-DD] [--as-of YYYY-MM-DD] (e.g. GDP, PAYEMS)",
+			Run:    fetchWith("fred", "revisions"),
+		}}},
+	}, nil
+}
+
+// sportsEntries lists the leagues with their schedules and game details.
+func (a *app) sportsEntries() ([]tui.Entry, error) {
+
+	var entries []tui.Entry
+	for _, league := range leagueTitles {
+		entries = append(entries, tui.Entry{Label: league.Title, Actions: []tui.Action{
+			{
+				Name:    "schedule",
+				Refresh: true,
+				Run: func(ctx context.Context, input string) (output.Result, error) {
+					return asResult(a.espn.Client.Schedule(ctx, league.League))
+				},
+			},
+			{
+				Key:    'g',
+				Name:   "game details",
+				Prompt: "Event number",
+				Run: func(ctx context.Context, input string) (output.Result, error) {
+					if _, err := strconv.Atoi(input); err != nil {
+						return nil, fmt.Errorf("invalid event number: %q", input)
+					}
+					return fetch(ctx, "espn", league.League, "--event", input)
+				},
+			},
+		}})
+	}
+
+	return entries, nil
+}
+
+// crmEntries lists the Salesforce object counts and contact search.
+func (a *app) crmEntries() ([]tui.Entry, error) {
+	return []tui.Entry{
+		{Label: "Salesforce object counts", Actions: []tui.Action{
+			{Name: "counts", Refresh: true, Run: fetchWith("sf", "counts")},
+		}},
+		{Label: "Search Salesforce contacts…", Actions: []tui.Action{{
+			Name:   "contacts",
+			Prompt: "Contact first or last name, email or account name",
+			Run: func(ctx context.Context, input string) (output.Result, error) {
+				return fetch(ctx, "sf", "contacts", "--filter", input)
+			},
+		}}},
+	}, nil
+}
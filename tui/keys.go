@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// keyCode identifies a key that isn't a printable character.
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyEnter
+	keyBackspace
+	keyTab
+	keyShiftTab
+	keyEsc
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPgUp
+	keyPgDn
+	keyHome
+	keyEnd
+	keyCtrlC
+)
+
+// key is a key press: a printable rune or one of the key codes.
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// csiKeys are the keys sent as escape sequences, by the bytes after ESC [ or ESC O.
+var csiKeys = map[string]keyCode{
+	"A":  keyUp,
+	"B":  keyDown,
+	"C":  keyRight,
+	"D":  keyLeft,
+	"H":  keyHome,
+	"F":  keyEnd,
+	"Z":  keyShiftTab,
+	"1~": keyHome,
+	"4~": keyEnd,
+	"5~": keyPgUp,
+	"6~": keyPgDn,
+}
+
+// parseKeys returns the keys in input read from a terminal in raw mode. Escape
+// sequences it doesn't know, e.g. function keys, are dropped.
+func parseKeys(input []byte) []key {
+
+	var keys []key
+	for len(input) > 0 {
+		b := input[0]
+		switch {
+		case b == 0x1b && len(input) > 1 && (input[1] == '[' || input[1] == 'O'):
+			// The sequence ends with a byte from @ to ~
+			end := 2
+			for end < len(input) && (input[end] < 0x40 || input[end] > 0x7e) {
+				end++
+			}
+			if end == len(input) {
+				return keys
+			}
+			if code, ok := csiKeys[string(input[2:end+1])]; ok {
+				keys = append(keys, key{code: code})
+			}
+			input = input[end+1:]
+			continue
+		case b == 0x1b:
+			keys = append(keys, key{code: keyEsc})
+		case b == 0x03:
+			keys = append(keys, key{code: keyCtrlC})
+		case b == '\r' || b == '\n':
+			keys = append(keys, key{code: keyEnter})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, key{code: keyBackspace})
+		case b == '\t':
+			keys = append(keys, key{code: keyTab})
+		case b == 0x0e: // Ctrl-N
+			keys = append(keys, key{code: keyDown})
+		case b == 0x10: // Ctrl-P
+			keys = append(keys, key{code: keyUp})
+		case b < 0x20:
+			// Other control keys aren't used
+		default:
+			r, size := utf8.DecodeRune(input)
+			keys = append(keys, key{code: keyRune, r: r})
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+
+	return keys
+}
+
+// readKeys sends the keys read from r until it can't be read.
+func readKeys(r io.Reader, keys chan<- key) {
+
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		for _, k := range parseKeys(buf[:n]) {
+			keys <- k
+		}
+		if err != nil {
+			close(keys)
+			return
+		}
+	}
+}
@@ -0,0 +1,637 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"polyapi/output"
+)
+
+// ANSI escape codes used to draw the screen.
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleRed     = "\x1b[31m"
+)
+
+// mode is what typed keys do: move around, edit the search filter or answer a prompt.
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeSearch
+	modePrompt
+)
+
+// request is an action to run in the background. Its response is only shown if no
+// other action was started since, which seq tells.
+type request struct {
+	seq    int
+	action Action
+	input  string
+	// refresh bypasses the response cache, when the user asks for a refresh
+	refresh bool
+}
+
+// response is the result of running a request.
+type response struct {
+	seq    int
+	result output.Result
+	err    error
+}
+
+// list is a pane's entries with the search filter and selection applied to them.
+type list struct {
+	entries  []Entry
+	err      error
+	filter   string
+	selected int
+	top      int
+}
+
+// visible returns the entries whose label contains the search filter, ignoring case.
+func (l *list) visible() []Entry {
+
+	if l.filter == "" {
+		return l.entries
+	}
+
+	filter := strings.ToLower(l.filter)
+	var entries []Entry
+	for _, entry := range l.entries {
+		if strings.Contains(strings.ToLower(entry.Label), filter) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// detail is the result shown on the right with the action that got it, so it can be
+// refreshed.
+type detail struct {
+	title   string
+	action  Action
+	input   string
+	pane    int
+	lines   []string
+	err     error
+	updated time.Time
+}
+
+// model is the state of the TUI. It's updated by key presses, responses and clock
+// ticks, and doesn't read or write the terminal itself, so it can be tested.
+type model struct {
+	panes []Pane
+	lists []list
+	pane  int
+
+	mode   mode
+	input  string
+	prompt Action
+	// promptTitle is the title of the detail shown once the prompt is answered
+	promptTitle string
+
+	detail     *detail
+	scroll     int
+	loading    bool
+	background bool
+	seq        int
+
+	status    string
+	statusErr bool
+
+	refreshEvery  time.Duration
+	width, height int
+}
+
+// newModel returns a model showing the first pane.
+func newModel(panes []Pane, refreshEvery time.Duration) *model {
+
+	m := &model{
+		panes:        panes,
+		lists:        make([]list, len(panes)),
+		refreshEvery: refreshEvery,
+		width:        80,
+		height:       24,
+	}
+	m.switchPane(0)
+	return m
+}
+
+// load lists a pane's entries again, keeping the selection where it was.
+func (m *model) load(pane int) {
+
+	l := &m.lists[pane]
+	l.entries, l.err = m.panes[pane].Entries()
+	l.selected = min(l.selected, max(len(l.visible())-1, 0))
+}
+
+// switchPane shows a pane, listing its entries again so they're up to date.
+func (m *model) switchPane(pane int) {
+	if len(m.panes) == 0 {
+		return
+	}
+	m.pane = (pane + len(m.panes)) % len(m.panes)
+	m.load(m.pane)
+}
+
+// selectedEntry returns the selected entry of the current pane.
+func (m *model) selectedEntry() (Entry, bool) {
+
+	if len(m.panes) == 0 {
+		return Entry{}, false
+	}
+	l := &m.lists[m.pane]
+	entries := l.visible()
+	if l.selected < 0 || l.selected >= len(entries) {
+		return Entry{}, false
+	}
+	return entries[l.selected], true
+}
+
+// move moves the selection by delta entries, staying within the list.
+func (m *model) move(delta int) {
+	l := &m.lists[m.pane]
+	l.selected = max(min(l.selected+delta, len(l.visible())-1), 0)
+}
+
+// setStatus shows a message in the status line until the next key press.
+func (m *model) setStatus(text string, isErr bool) {
+	m.status = text
+	m.statusErr = isErr
+}
+
+// handleKey updates the model for a key press. It returns the action to run, if the
+// key started one, and whether to quit.
+func (m *model) handleKey(k key) (*request, bool) {
+
+	if k.code == keyCtrlC {
+		return nil, true
+	}
+	m.setStatus("", false)
+
+	switch m.mode {
+	case modeSearch:
+		return m.searchKey(k), false
+	case modePrompt:
+		return m.promptKey(k), false
+	}
+
+	switch k.code {
+	case keyTab, keyRight:
+		m.switchPane(m.pane + 1)
+	case keyShiftTab, keyLeft:
+		m.switchPane(m.pane - 1)
+	case keyUp:
+		m.move(-1)
+	case keyDown:
+		m.move(1)
+	case keyHome:
+		m.move(-len(m.lists[m.pane].entries))
+	case keyEnd:
+		m.move(len(m.lists[m.pane].entries))
+	case keyPgUp:
+		m.scroll = max(m.scroll-m.pageSize(), 0)
+	case keyPgDn:
+		m.scroll += m.pageSize()
+	case keyEsc:
+		m.lists[m.pane].filter = ""
+	case keyEnter:
+		if entry, ok := m.selectedEntry(); ok && len(entry.Actions) > 0 {
+			return m.run(entry, 0), false
+		}
+	case keyRune:
+		switch {
+		case k.r == 'q':
+			return nil, true
+		case k.r == '/':
+			m.mode = modeSearch
+			m.input = m.lists[m.pane].filter
+		case k.r == 'r':
+			return m.refresh(), false
+		case k.r >= '1' && k.r <= '9':
+			if n := int(k.r - '1'); n < len(m.panes) {
+				m.switchPane(n)
+			}
+		default:
+			entry, ok := m.selectedEntry()
+			if !ok {
+				break
+			}
+			for i, action := range entry.Actions {
+				if action.Key == k.r {
+					return m.run(entry, i), false
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// searchKey edits the search filter, which is applied as it's typed.
+func (m *model) searchKey(k key) *request {
+
+	l := &m.lists[m.pane]
+
+	switch k.code {
+	case keyEnter:
+		m.mode = modeBrowse
+		return nil
+	case keyEsc:
+		m.mode = modeBrowse
+		m.input = ""
+	case keyUp:
+		m.move(-1)
+		return nil
+	case keyDown:
+		m.move(1)
+		return nil
+	case keyBackspace:
+		m.input = trimLastRune(m.input)
+	case keyRune:
+		m.input += string(k.r)
+	default:
+		return nil
+	}
+
+	l.filter = m.input
+	l.selected, l.top = 0, 0
+	return nil
+}
+
+// promptKey edits the answer to a prompt and runs its action once it's entered.
+func (m *model) promptKey(k key) *request {
+
+	switch k.code {
+	case keyEnter:
+		m.mode = modeBrowse
+		return m.start(m.promptTitle, m.prompt, strings.TrimSpace(m.input), false)
+	case keyEsc:
+		m.mode = modeBrowse
+		m.setStatus("Cancelled", false)
+	case keyBackspace:
+		m.input = trimLastRune(m.input)
+	case keyRune:
+		m.input += string(k.r)
+	}
+
+	return nil
+}
+
+// run runs an entry's action, first prompting for its input if it has a prompt.
+// The title of the result is the entry's label, with the action's name unless it's
+// the entry's first action.
+func (m *model) run(entry Entry, i int) *request {
+
+	action := entry.Actions[i]
+	title := strings.TrimSuffix(entry.Label, "…")
+	if i > 0 {
+		title += " - " + action.Name
+	}
+
+	if action.Prompt != "" {
+		m.mode = modePrompt
+		m.input = ""
+		m.prompt = action
+		m.promptTitle = title
+		return nil
+	}
+
+	return m.start(title, action, "", false)
+}
+
+// start shows that an action is loading and returns the request to run it.
+func (m *model) start(title string, action Action, input string, refresh bool) *request {
+
+	m.seq++
+	m.loading = true
+	m.background = false
+
+	// Keep showing the result being refreshed until the new one arrives
+	if refresh && m.detail != nil {
+		m.detail.err = nil
+	} else {
+		m.detail = &detail{title: title, action: action, input: input, pane: m.pane}
+		m.scroll = 0
+	}
+
+	return &request{seq: m.seq, action: action, input: input, refresh: refresh}
+}
+
+// refresh runs the action of the result shown again, bypassing the response cache.
+func (m *model) refresh() *request {
+
+	if m.detail == nil || m.detail.action.Run == nil {
+		return nil
+	}
+	if m.detail.action.Reload {
+		m.setStatus("This result can't be refreshed", false)
+		return nil
+	}
+	return m.start(m.detail.title, m.detail.action, m.detail.input, true)
+}
+
+// handleResponse shows the result of the latest request, ignoring the results of
+// requests started before it.
+func (m *model) handleResponse(r response, now time.Time) {
+
+	if r.seq != m.seq || m.detail == nil {
+		return
+	}
+
+	m.loading = false
+	m.detail.updated = now
+
+	if r.err != nil {
+		if m.background {
+			m.setStatus("Refreshing failed: "+r.err.Error(), true)
+			return
+		}
+		m.detail.lines = nil
+		m.detail.err = r.err
+		return
+	}
+
+	m.detail.err = nil
+	m.detail.lines = resultLines(r.result)
+
+	if m.detail.action.Reload {
+		m.load(m.detail.pane)
+	}
+}
+
+// tick refreshes the result shown in the background once it's older than the refresh
+// interval, if its action can be refreshed. Results are fetched through the response
+// cache, so the providers' cache TTLs still limit how often the APIs are called.
+func (m *model) tick(now time.Time) *request {
+
+	d := m.detail
+	if m.refreshEvery <= 0 || d == nil || !d.action.Refresh || m.loading || now.Sub(d.updated) < m.refreshEvery {
+		return nil
+	}
+
+	m.seq++
+	m.loading = true
+	m.background = true
+	return &request{seq: m.seq, action: d.action, input: d.input}
+}
+
+// resultLines renders a result as a table and splits it into lines.
+func resultLines(result output.Result) []string {
+
+	if result == nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	result.PrintTable(&buf)
+
+	text := strings.ReplaceAll(buf.String(), "\t", "    ")
+	text = strings.ReplaceAll(text, "\r", "")
+	text = strings.Trim(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// bodyHeight is the number of rows between the tabs and the status line.
+func (m *model) bodyHeight() int {
+	return max(m.height-2, 1)
+}
+
+// pageSize is how many lines PgUp and PgDn scroll the result.
+func (m *model) pageSize() int {
+	return max(m.bodyHeight()-2, 1)
+}
+
+// listWidth is the width of the list of entries on the left.
+func (m *model) listWidth() int {
+	if m.width < 60 {
+		return m.width / 2
+	}
+	return min(max(m.width/3, 24), 44)
+}
+
+// view returns the rows of the screen: the tabs, the list of entries on the left and
+// the result on the right, and the status line.
+func (m *model) view() []string {
+
+	rows := make([]string, 0, m.height)
+	rows = append(rows, m.tabs())
+
+	left := m.listRows()
+	right := m.detailRows()
+	for i := 0; i < m.bodyHeight(); i++ {
+		rows = append(rows, left[i]+styleDim+"│"+styleReset+right[i])
+	}
+
+	rows = append(rows, m.statusLine())
+	return rows[:min(len(rows), m.height)]
+}
+
+// tabs returns the row of pane titles with the current pane highlighted.
+func (m *model) tabs() string {
+
+	var b strings.Builder
+	width := 0
+	for i, pane := range m.panes {
+		tab := fmt.Sprintf(" %d %s ", i+1, pane.Title)
+		if width+utf8.RuneCountInString(tab) > m.width {
+			break
+		}
+		width += utf8.RuneCountInString(tab)
+		if i == m.pane {
+			b.WriteString(styleReverse + tab + styleReset)
+		} else {
+			b.WriteString(tab)
+		}
+	}
+	b.WriteString(strings.Repeat(" ", max(m.width-width, 0)))
+	return b.String()
+}
+
+// listRows returns the rows of the list of entries, scrolled to show the selection.
+// The first row counts the entries matching the search filter.
+func (m *model) listRows() []string {
+
+	width := m.listWidth()
+	height := m.bodyHeight()
+	rows := make([]string, height)
+	for i := range rows {
+		rows[i] = fit("", width)
+	}
+	if len(m.panes) == 0 {
+		return rows
+	}
+
+	l := &m.lists[m.pane]
+	entries := l.visible()
+
+	switch {
+	case l.err != nil:
+		rows[0] = styleRed + fit(" "+l.err.Error(), width) + styleReset
+		return rows
+	case l.filter != "" || m.mode == modeSearch:
+		rows[0] = styleDim + fit(fmt.Sprintf(" /%s  %d of %d", l.filter, len(entries), len(l.entries)), width) + styleReset
+	default:
+		count := fmt.Sprintf(" %d entries", len(entries))
+		if len(entries) == 1 {
+			count = " 1 entry"
+		}
+		rows[0] = styleDim + fit(count, width) + styleReset
+	}
+
+	// Scroll the list so the selection is in view
+	visibleRows := height - 1
+	if l.selected < l.top {
+		l.top = l.selected
+	}
+	if visibleRows > 0 && l.selected >= l.top+visibleRows {
+		l.top = l.selected - visibleRows + 1
+	}
+
+	for i := 0; i < visibleRows && l.top+i < len(entries); i++ {
+		n := l.top + i
+		if n == l.selected {
+			rows[i+1] = styleReverse + fit(" "+entries[n].Label, width) + styleReset
+		} else {
+			rows[i+1] = fit(" "+entries[n].Label, width)
+		}
+	}
+
+	return rows
+}
+
+// detailRows returns the rows of the result shown, scrolled by PgUp and PgDn, under
+// its title and when it was updated.
+func (m *model) detailRows() []string {
+
+	width := m.width - m.listWidth() - 1
+	height := m.bodyHeight()
+	rows := make([]string, height)
+	for i := range rows {
+		rows[i] = fit("", width)
+	}
+
+	if m.detail == nil {
+		help := []string{
+			"",
+			" Select an entry with ↑ and ↓ and press Enter to show it.",
+			" Tab, ← and → or the numbers switch panes, and / searches the list.",
+		}
+		for i := 0; i < len(help) && i < height; i++ {
+			rows[i] = fit(help[i], width)
+		}
+		return rows
+	}
+
+	d := m.detail
+	title := " " + d.title
+	switch {
+	case m.loading:
+		title += "  loading…"
+	case !d.updated.IsZero():
+		title += "  updated " + d.updated.Format("3:04:05 PM")
+	}
+	rows[0] = styleBold + fit(title, width) + styleReset
+
+	if d.err != nil {
+		lines := wrap(d.err.Error(), width-1)
+		for i := 0; i < len(lines) && i+2 < height; i++ {
+			rows[i+2] = styleRed + fit(" "+lines[i], width) + styleReset
+		}
+		return rows
+	}
+
+	m.scroll = max(min(m.scroll, len(d.lines)-(height-1)), 0)
+	for i := 0; i+1 < height && m.scroll+i < len(d.lines); i++ {
+		rows[i+1] = fit(" "+d.lines[m.scroll+i], width)
+	}
+
+	return rows
+}
+
+// statusLine returns the prompt or search being typed, the latest message, or help
+// for the keys of the selected entry.
+func (m *model) statusLine() string {
+
+	switch {
+	case m.mode == modePrompt:
+		return fit(" "+m.prompt.Prompt+": "+m.input+"_", m.width)
+	case m.mode == modeSearch:
+		return fit(" Search: "+m.input+"_", m.width)
+	case m.status != "" && m.statusErr:
+		return styleRed + fit(" "+m.status, m.width) + styleReset
+	case m.status != "":
+		return fit(" "+m.status, m.width)
+	}
+
+	help := []string{"q quit", "tab pane", "/ search"}
+	if entry, ok := m.selectedEntry(); ok {
+		for i, action := range entry.Actions {
+			if i == 0 {
+				help = append(help, "enter "+action.Name)
+			} else if action.Key != 0 {
+				help = append(help, string(action.Key)+" "+action.Name)
+			}
+		}
+	}
+	if m.detail != nil && !m.detail.action.Reload {
+		help = append(help, "r refresh")
+	}
+	if m.detail != nil && len(m.detail.lines) > m.bodyHeight()-1 {
+		help = append(help, "pgup/pgdn scroll")
+	}
+
+	return styleDim + fit(" "+strings.Join(help, "  "), m.width) + styleReset
+}
+
+// fit pads or truncates text to width columns, replacing control characters so they
+// can't move the cursor.
+func fit(text string, width int) string {
+
+	if width <= 0 {
+		return ""
+	}
+
+	runes := []rune(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text))
+
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return string(runes) + strings.Repeat(" ", width-len(runes))
+}
+
+// wrap splits text into lines of at most width characters, e.g. a long error.
+func wrap(text string, width int) []string {
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		runes := []rune(line)
+		for len(runes) > width && width > 0 {
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+// trimLastRune removes the last character of s.
+func trimLastRune(s string) string {
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[:len(s)-size]
+}
@@ -0,0 +1,191 @@
+// Package tui is a full-screen terminal UI with a tab per pane, e.g. weather or
+// markets, a searchable list of each pane's entries, such as saved addresses, and the
+// result of the selected entry's action, refreshed in the background.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"polyapi/httpx"
+	"polyapi/output"
+)
+
+// Pane is a tab of the TUI with a list of entries, e.g. the saved addresses.
+type Pane struct {
+	Title string
+	// Entries lists the pane's entries. It's called when the pane is shown and after
+	// an action that changes them, e.g. deleting an address.
+	Entries func() ([]Entry, error)
+}
+
+// Entry is an item in a pane's list and the actions that can be run on it. Enter
+// runs the first action and the others are run with their keys.
+type Entry struct {
+	Label   string
+	Actions []Action
+}
+
+// Action gets a result to show for an entry, e.g. the weather for an address.
+type Action struct {
+	// Key runs the action. q, r, / and the digits are used by the TUI itself.
+	Key  rune
+	Name string
+	// Prompt asks for the input passed to Run. Without a prompt the input is empty.
+	Prompt string
+	Run    func(ctx context.Context, input string) (output.Result, error)
+	// Refresh runs the action again in the background while its result is shown.
+	Refresh bool
+	// Reload lists the pane's entries again after the action, e.g. after a delete.
+	// Such actions aren't run again by a refresh.
+	Reload bool
+}
+
+// Message is a result that's just text, e.g. that an address was deleted.
+type Message string
+
+// PrintTable prints the message.
+func (m Message) PrintTable(w io.Writer) {
+	fmt.Fprintln(w, string(m))
+}
+
+// CSVHeader returns the message column.
+func (m Message) CSVHeader() []string {
+	return []string{"message"}
+}
+
+// CSVRows returns the message.
+func (m Message) CSVRows() [][]string {
+	return [][]string{{string(m)}}
+}
+
+// tickInterval is how often the terminal size and background refreshes are checked.
+const tickInterval = 250 * time.Millisecond
+
+// Run shows the panes in the terminal until the user quits. Results shown by actions
+// that can be refreshed are run again every refreshEvery.
+func Run(ctx context.Context, panes []Pane, refreshEvery time.Duration) error {
+
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		return errors.New("the interactive mode needs a terminal; run `polyapi help` for the commands")
+	}
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(in, state)
+
+	// Use the alternate screen, so the shell's output is back after quitting
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	// Results are drawn in columns, so they're not colored
+	colors := output.Colors
+	output.Colors = false
+	defer func() { output.Colors = colors }()
+
+	// Providers log warnings, which would scroll the screen, so they're shown in the
+	// status line instead
+	logs := make(chan string, 16)
+	flags := log.Flags()
+	log.SetFlags(0)
+	log.SetOutput(logWriter(logs))
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys := make(chan key)
+	go readKeys(os.Stdin, keys)
+	responses := make(chan response)
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	m := newModel(panes, refreshEvery)
+	var frame string
+
+	for {
+		if width, height, err := term.GetSize(out); err == nil && (width != m.width || height != m.height) {
+			m.width, m.height = width, height
+			frame = ""
+			fmt.Print("\x1b[2J")
+		}
+		if next := render(m.view()); next != frame {
+			frame = next
+			fmt.Print(frame)
+		}
+
+		var req *request
+		select {
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			var quit bool
+			if req, quit = m.handleKey(k); quit {
+				return nil
+			}
+		case r := <-responses:
+			m.handleResponse(r, time.Now())
+		case line := <-logs:
+			m.setStatus(line, false)
+		case now := <-ticker.C:
+			req = m.tick(now)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if req != nil {
+			go run(ctx, *req, responses)
+		}
+	}
+}
+
+// run runs a request's action and sends its response, unless the TUI has quit.
+func run(ctx context.Context, req request, responses chan<- response) {
+
+	if req.refresh {
+		ctx = httpx.WithCacheMode(ctx, httpx.CacheRefresh)
+	}
+
+	result, err := req.action.Run(ctx, req.input)
+	select {
+	case responses <- response{seq: req.seq, result: result, err: err}:
+	case <-ctx.Done():
+	}
+}
+
+// render returns the escape codes that draw the rows of the screen, each at the start
+// of its line.
+func render(rows []string) string {
+
+	var b strings.Builder
+	for i, row := range rows {
+		fmt.Fprintf(&b, "\x1b[%d;1H%s", i+1, row)
+	}
+	return b.String()
+}
+
+// logWriter sends each line logged to the TUI, dropping lines while it's busy.
+type logWriter chan<- string
+
+func (w logWriter) Write(p []byte) (int, error) {
+	select {
+	case w <- strings.TrimSpace(string(p)):
+	default:
+	}
+	return len(p), nil
+}
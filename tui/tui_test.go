@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"polyapi/output"
+)
+
+func TestParseKeys(t *testing.T) {
+	input := []byte("a\x1b[A\x1b[B\r\x7f\t\x1b[Z\x1b[5~\x1b[15~é\x03\x1b")
+	want := []key{
+		{code: keyRune, r: 'a'},
+		{code: keyUp},
+		{code: keyDown},
+		{code: keyEnter},
+		{code: keyBackspace},
+		{code: keyTab},
+		{code: keyShiftTab},
+		{code: keyPgUp},
+		// F5 isn't used, so it's dropped
+		{code: keyRune, r: 'é'},
+		{code: keyCtrlC},
+		{code: keyEsc},
+	}
+
+	if got := parseKeys(input); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseKeys() = %v, want %v", got, want)
+	}
+}
+
+// testPanes returns a pane of saved addresses, which can be deleted, and a pane of
+// leagues whose schedules count how often they're fetched.
+func testPanes(addresses *[]string, fetches *int) []Pane {
+
+	addressEntries := func() ([]Entry, error) {
+		var entries []Entry
+		for i, address := range *addresses {
+			entries = append(entries, Entry{Label: address, Actions: []Action{
+				{Name: "weather", Refresh: true, Run: func(ctx context.Context, input string) (output.Result, error) {
+					*fetches++
+					return Message("Sunny at " + address), nil
+				}},
+				{Key: 'd', Name: "delete", Prompt: "Delete this address? (y/n)", Reload: true, Run: func(ctx context.Context, input string) (output.Result, error) {
+					if input != "y" {
+						return Message("Not deleted"), nil
+					}
+					*addresses = append((*addresses)[:i], (*addresses)[i+1:]...)
+					return Message("Deleted"), nil
+				}},
+			}})
+		}
+		return entries, nil
+	}
+
+	leagueEntries := func() ([]Entry, error) {
+		return nil, errors.New("no leagues")
+	}
+
+	return []Pane{{Title: "Weather", Entries: addressEntries}, {Title: "Sports", Entries: leagueEntries}}
+}
+
+// press sends a key to the model and runs the action it started, if any.
+func press(t *testing.T, m *model, k key) {
+	t.Helper()
+
+	req, quit := m.handleKey(k)
+	if quit {
+		t.Fatal("the model quit")
+	}
+	if req != nil {
+		result, err := req.action.Run(context.Background(), req.input)
+		m.handleResponse(response{seq: req.seq, result: result, err: err}, time.Now())
+	}
+}
+
+// typeText sends each character of text to the model.
+func typeText(t *testing.T, m *model, text string) {
+	t.Helper()
+	for _, r := range text {
+		press(t, m, key{code: keyRune, r: r})
+	}
+}
+
+func TestModelNavigation(t *testing.T) {
+	addresses := []string{"432 Park Ave, New York", "1 Market St, San Francisco", "100 Main St, Boston"}
+	var fetches int
+	m := newModel(testPanes(&addresses, &fetches), time.Minute)
+
+	press(t, m, key{code: keyDown})
+	press(t, m, key{code: keyEnter})
+	if m.detail == nil || m.detail.title != "1 Market St, San Francisco" {
+		t.Fatalf("detail = %+v, want the second address", m.detail)
+	}
+	if want := []string{"Sunny at 1 Market St, San Francisco"}; !reflect.DeepEqual(m.detail.lines, want) {
+		t.Errorf("lines = %q, want %q", m.detail.lines, want)
+	}
+
+	// Searching filters the list as it's typed
+	press(t, m, key{code: keyRune, r: '/'})
+	typeText(t, m, "BOST")
+	press(t, m, key{code: keyEnter})
+	if entry, _ := m.selectedEntry(); entry.Label != "100 Main St, Boston" {
+		t.Errorf("selected %q after searching, want the Boston address", entry.Label)
+	}
+	press(t, m, key{code: keyEsc})
+	if n := len(m.lists[0].visible()); n != 3 {
+		t.Errorf("%d entries after clearing the search, want 3", n)
+	}
+
+	// A pane's error is shown in its list
+	press(t, m, key{code: keyTab})
+	if m.pane != 1 {
+		t.Fatalf("pane = %d after tab, want 1", m.pane)
+	}
+	if rows := m.view(); !strings.Contains(rows[1], "no leagues") {
+		t.Errorf("first row = %q, want the pane's error", rows[1])
+	}
+	press(t, m, key{code: keyRune, r: '1'})
+	if m.pane != 0 {
+		t.Errorf("pane = %d after 1, want 0", m.pane)
+	}
+
+	if _, quit := m.handleKey(key{code: keyRune, r: 'q'}); !quit {
+		t.Error("q didn't quit")
+	}
+}
+
+func TestModelPrompt(t *testing.T) {
+	addresses := []string{"432 Park Ave, New York", "1 Market St, San Francisco"}
+	var fetches int
+	m := newModel(testPanes(&addresses, &fetches), time.Minute)
+
+	// Escape cancels the prompt
+	press(t, m, key{code: keyRune, r: 'd'})
+	if m.mode != modePrompt {
+		t.Fatalf("mode = %d after d, want the prompt", m.mode)
+	}
+	press(t, m, key{code: keyEsc})
+	if m.mode != modeBrowse || len(addresses) != 2 {
+		t.Fatalf("mode = %d with %d addresses after escape, want nothing deleted", m.mode, len(addresses))
+	}
+
+	// The entries are listed again after the delete
+	press(t, m, key{code: keyRune, r: 'd'})
+	typeText(t, m, "y")
+	press(t, m, key{code: keyEnter})
+	if len(addresses) != 1 || len(m.lists[0].entries) != 1 {
+		t.Fatalf("%d addresses and %d entries after the delete, want 1", len(addresses), len(m.lists[0].entries))
+	}
+	if m.detail.title != "432 Park Ave, New York - delete" || m.detail.lines[0] != "Deleted" {
+		t.Errorf("detail = %q %q, want the delete's message", m.detail.title, m.detail.lines)
+	}
+}
+
+func TestModelRefresh(t *testing.T) {
+	addresses := []string{"432 Park Ave, New York"}
+	var fetches int
+	m := newModel(testPanes(&addresses, &fetches), time.Minute)
+
+	press(t, m, key{code: keyEnter})
+	updated := m.detail.updated
+
+	if req := m.tick(updated.Add(30 * time.Second)); req != nil {
+		t.Error("refreshed before the interval")
+	}
+	req := m.tick(updated.Add(time.Minute))
+	if req == nil {
+		t.Fatal("not refreshed after the interval")
+	}
+	if req.refresh {
+		t.Error("a background refresh bypassed the response cache")
+	}
+
+	// A result started after the refresh replaces it
+	press(t, m, key{code: keyRune, r: 'r'})
+	m.handleResponse(response{seq: req.seq, err: errors.New("stale")}, time.Now())
+	if m.detail.err != nil || m.status != "" {
+		t.Errorf("the stale refresh was shown: %v %q", m.detail.err, m.status)
+	}
+	if fetches != 2 {
+		t.Errorf("%d fetches, want 2", fetches)
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcdef", 4, "abc…"},
+		{"a\x1b[2Jb", 6, "a [2Jb"},
+		{"°F", 3, "°F "},
+	}
+
+	for _, tt := range tests {
+		if got := fit(tt.text, tt.width); got != tt.want {
+			t.Errorf("fit(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package weather
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"polyapi/output"
+	"polyapi/provider"
+	"polyapi/store"
+)
+
+// Provider looks up the weather for an address and saves the address
+// with its latest temperature.
+type Provider struct {
+	Client *Client
+	Store  *store.Store
+}
+
+// NewProvider returns a weather provider that saves addresses to s.
+func NewProvider(client *Client, s *store.Store) *Provider {
+	return &Provider{Client: client, Store: s}
+}
+
+func (p *Provider) Name() string { return "weather" }
+
+func (p *Provider) Description() string {
+	return "NOAA weather for an address (weather --address ADDRESS [--forecast] [--hourly])"
+}
+
+func (p *Provider) RequiredConfig() []string { return nil }
+
+// Fetch geocodes --address and returns the weather for it, with the forecast and
+// hourly forecast when --forecast and --hourly are set.
+func (p *Provider) Fetch(ctx context.Context, args []string) (output.Result, error) {
+
+	flags := flag.NewFlagSet("weather", flag.ContinueOnError)
+	address := flags.String("address", "", "street address to geocode, e.g. \"432 Park Ave, 10022\"")
+	forecast := flags.Bool("forecast", false, "also print the forecast for the next 2 days and a week out")
+	hourly := flags.Bool("hourly", false, "also print the forecast for the next 12 hours")
+	if err := provider.ParseFlags(ctx, flags, args); err != nil {
+		return nil, err
+	}
+
+	// Allow the address to be passed as plain arguments as well
+	if *address == "" {
+		*address = strings.Join(flags.Args(), " ")
+	}
+	if *address == "" {
+		return nil, provider.Usagef("weather requires --address")
+	}
+
+	saved, err := p.SaveAddress(ctx, *address)
+	if err != nil {
+		return nil, err
+	}
+
+	report, points, err := p.Weather(ctx, saved)
+	if err != nil {
+		return nil, err
+	}
+	report.Address = saved.MatchedAddress
+
+	if *forecast {
+		report.Forecast, err = p.Client.Forecast(ctx, points)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if *hourly {
+		report.Hourly, err = p.Client.HourlyForecast(ctx, points)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// SaveAddress geocodes an address and saves the matched address to the database.
+func (p *Provider) SaveAddress(ctx context.Context, address string) (store.Address, error) {
+
+	match, err := p.Client.Geocode(ctx, address)
+	if err != nil {
+		return store.Address{}, err
+	}
+
+	return p.Store.SaveAddress(match.Address, match.Latitude, match.Longitude)
+}
+
+// Locate returns a saved address with its state and metro area, looking them up
+// and saving them the first time.
+func (p *Provider) Locate(ctx context.Context, address store.Address) (store.Address, error) {
+
+	if address.Region.StateFIPS != "" {
+		return address, nil
+	}
+
+	region, err := p.Client.Region(ctx, address.Latitude, address.Longitude)
+	if err != nil {
+		return address, fmt.Errorf("error looking up region: %w", err)
+	}
+
+	address.Region = store.Region{StateFIPS: region.StateFIPS, State: region.State, CBSA: region.CBSA, Metro: region.Metro}
+	if err := p.Store.UpdateRegion(address.Id, address.Region); err != nil {
+		return address, err
+	}
+
+	return address, nil
+}
+
+// Weather gets the weather for a saved address and records its latest temperature
+// and a reading in its temperature history.
+// It also returns the NOAA points response so callers can fetch forecasts.
+func (p *Provider) Weather(ctx context.Context, address store.Address) (Report, PointsResponse, error) {
+
+	report, points, err := p.Client.Report(ctx, fmt.Sprintf("%.8f", address.Latitude), fmt.Sprintf("%.8f", address.Longitude))
+	if err != nil {
+		return report, points, err
+	}
+
+	p.recordTemperature(ctx, address, report, points)
+
+	// Get first hourly temperature and update the address record in the database
+	temperature, err := p.Client.CurrentTemperature(ctx, points)
+	if err != nil {
+		log.Printf("Error getting latest temperature: %v", err)
+		return report, points, nil
+	}
+	if err := p.Store.UpdateTemperature(address.Id, temperature); err != nil {
+		log.Print(err)
+		return report, points, nil
+	}
+	report.LatestTemperature = temperature
+
+	return report, points, nil
+}
+
+// recordTemperature adds the temperature at the nearest station reporting one to the
+// address's history, or the current hourly forecast if no station has a temperature.
+func (p *Provider) recordTemperature(ctx context.Context, address store.Address, report Report, points PointsResponse) {
+
+	reading := store.TemperatureReading{AddressId: address.Id}
+
+	for _, station := range report.Stations {
+		if station.TemperatureF == nil {
+			continue
+		}
+		recordedAt, err := time.Parse(time.RFC3339, station.Timestamp)
+		if err != nil {
+			continue
+		}
+		reading.Temperature = *station.TemperatureF
+		reading.Source = station.Identifier
+		reading.RecordedAt = recordedAt
+		break
+	}
+
+	if reading.Source == "" {
+		hourly, err := p.Client.HourlyForecast(ctx, points)
+		if err != nil {
+			log.Printf("Error getting temperature for history: %v", err)
+			return
+		}
+		recordedAt, err := time.Parse(time.RFC3339, hourly[0].StartTime)
+		if err != nil {
+			log.Printf("Error getting temperature for history: %v", err)
+			return
+		}
+		reading.Temperature = float64(hourly[0].Temperature)
+		if hourly[0].TemperatureUnit == "C" {
+			reading.Temperature = celsiusToFahrenheit(reading.Temperature)
+		}
+		reading.Source = "forecast"
+		reading.RecordedAt = recordedAt
+	}
+
+	if err := p.Store.AddTemperatureReading(reading); err != nil {
+		log.Printf("Error recording temperature: %v", err)
+	}
+}
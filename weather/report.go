@@ -0,0 +1,185 @@
+package weather
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"polyapi/output"
+)
+
+// ForecastPeriod is a single period of a NOAA forecast.
+// A period is a 12-hour time frame, or an hour for the hourly forecast.
+type ForecastPeriod struct {
+	Name             string `json:"name,omitempty"`
+	StartTime        string `json:"start_time"`
+	Temperature      int    `json:"temperature"`
+	TemperatureUnit  string `json:"temperature_unit"`
+	ShortForecast    string `json:"short_forecast"`
+	DetailedForecast string `json:"detailed_forecast,omitempty"`
+}
+
+// StationObservation is the latest observation from a NOAA weather station.
+// Temperatures are converted to Fahrenheit; missing measurements are omitted.
+type StationObservation struct {
+	Name          string   `json:"name"`
+	Identifier    string   `json:"identifier"`
+	MapsURL       string   `json:"maps_url"`
+	Timestamp     string   `json:"timestamp,omitempty"`
+	TemperatureF  *float64 `json:"temperature_f,omitempty"`
+	DewpointF     *float64 `json:"dewpoint_f,omitempty"`
+	WindSpeed     *float64 `json:"wind_speed_kmh,omitempty"`
+	WindDirection *float64 `json:"wind_direction_deg,omitempty"`
+	Humidity      *float64 `json:"humidity_pct,omitempty"`
+	Pressure      *float64 `json:"pressure_pa,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// Report is the weather for a location: observations from the nearest stations
+// and, when requested, the forecast and hourly forecast.
+type Report struct {
+	Address           string               `json:"address,omitempty"`
+	Latitude          float64              `json:"latitude"`
+	Longitude         float64              `json:"longitude"`
+	MapsURL           string               `json:"maps_url"`
+	LatestTemperature string               `json:"latest_temperature,omitempty"`
+	Stations          []StationObservation `json:"stations,omitempty"`
+	Forecast          []ForecastPeriod     `json:"forecast,omitempty"`
+	Hourly            []ForecastPeriod     `json:"hourly,omitempty"`
+}
+
+// newStationObservation converts a NOAA observation to Fahrenheit and drops missing measurements.
+func newStationObservation(observation Properties) StationObservation {
+
+	var result StationObservation
+
+	result.Timestamp = observation.Timestamp
+	if observation.Temperature.Value != nil {
+		temperature := celsiusToFahrenheit(*observation.Temperature.Value)
+		result.TemperatureF = &temperature
+	}
+	if observation.Dewpoint.Value != nil {
+		dewpoint := celsiusToFahrenheit(*observation.Dewpoint.Value)
+		result.DewpointF = &dewpoint
+	}
+	result.WindSpeed = observation.WindSpeed.Value
+	result.WindDirection = observation.WindDirection.Value
+	result.Humidity = observation.RelativeHumidity.Value
+	result.Pressure = observation.BarometricPressure.Value
+	result.Description = observation.TextDescription
+
+	return result
+}
+
+// PrintTable prints the station observations followed by any forecasts.
+func (r Report) PrintTable(w io.Writer) {
+
+	if len(r.Stations) > 0 {
+		fmt.Fprintln(w, "\nNOAA weather stations: (sorted by nearest to farthest)")
+		fmt.Fprintln(w)
+		for i, station := range r.Stations {
+			fmt.Fprintf(w, "Station %d: %s\n", i+1, station.Name)
+			fmt.Fprintf(w, "  Identifier: %s\n", station.Identifier)
+			fmt.Fprintf(w, "  Location: %s\n", station.MapsURL)
+			if station.Error != "" {
+				fmt.Fprintln(w, "Error fetching observation data:", station.Error)
+				continue
+			}
+			printObservation(w, station)
+		}
+
+		if r.LatestTemperature != "" {
+			fmt.Fprintln(w)
+			fmt.Fprintf(w, "Address record updated successfully with latest temperature %s!\n", r.LatestTemperature)
+		}
+
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%s\n", r.MapsURL)
+	}
+
+	if len(r.Forecast) > 0 {
+		fmt.Fprintln(w, "\nForecast: (next 2 days and a week out)")
+		fmt.Fprintln(w)
+		for _, period := range r.Forecast {
+			startTime := strings.Split(period.StartTime, "T")[0]
+			fmt.Fprintf(w, "%s (%s)  %d%s\n", startTime, period.Name, period.Temperature, period.TemperatureUnit)
+			fmt.Fprintf(w, "  %s\n\n", period.DetailedForecast)
+		}
+	}
+
+	if len(r.Hourly) > 0 {
+		fmt.Fprintln(w, "\nNext 12 hours:")
+		fmt.Fprintln(w)
+		for _, period := range r.Hourly {
+			fmt.Fprintf(w, "%s %d%s\n", output.FormatTime(period.StartTime), period.Temperature, period.TemperatureUnit)
+			fmt.Fprintf(w, " - %s\n", period.ShortForecast)
+			fmt.Fprintf(w, "\n")
+		}
+	}
+}
+
+// Print observation information
+func printObservation(w io.Writer, observation StationObservation) {
+
+	var observationDateTime = output.ExtractDate(observation.Timestamp) + " at " + output.FormatTime(observation.Timestamp)
+
+	fmt.Fprintf(w, "  Timestamp: %s\n", observationDateTime)
+	if observation.TemperatureF != nil {
+		fmt.Fprintf(w, "  Temperature: %.2f°F\n", *observation.TemperatureF)
+	}
+	if observation.DewpointF != nil {
+		fmt.Fprintf(w, "  Dewpoint: %.2f°F\n", *observation.DewpointF)
+	}
+	if observation.WindSpeed != nil {
+		fmt.Fprintf(w, "  Wind Speed: %.2f km/h\n", *observation.WindSpeed)
+	}
+	if observation.WindDirection != nil {
+		fmt.Fprintf(w, "  Wind Direction: %.2f°\n", *observation.WindDirection)
+	}
+	if observation.Humidity != nil {
+		fmt.Fprintf(w, "  Humidity: %.2f%%\n", *observation.Humidity)
+	}
+	if observation.Pressure != nil {
+		fmt.Fprintf(w, "  Pressure: %.2f Pa\n", *observation.Pressure)
+	}
+	if observation.Description != "" {
+		fmt.Fprintf(w, "  Description: %s\n", observation.Description)
+	}
+	fmt.Fprintln(w)
+}
+
+// CSVHeader returns the weather columns; each row is a station observation or a forecast period.
+func (r Report) CSVHeader() []string {
+	return []string{"section", "name", "station", "time", "temperature", "unit", "dewpoint_f", "wind_speed_kmh", "wind_direction_deg", "humidity_pct", "pressure_pa", "description"}
+}
+
+// CSVRows returns one row per station observation and forecast period.
+func (r Report) CSVRows() [][]string {
+	var rows [][]string
+	for _, station := range r.Stations {
+		description := station.Description
+		if station.Error != "" {
+			description = station.Error
+		}
+		rows = append(rows, []string{"station", station.Name, station.Identifier, station.Timestamp,
+			output.FormatOptional(station.TemperatureF), "F", output.FormatOptional(station.DewpointF), output.FormatOptional(station.WindSpeed),
+			output.FormatOptional(station.WindDirection), output.FormatOptional(station.Humidity), output.FormatOptional(station.Pressure), description})
+	}
+	for _, period := range r.Forecast {
+		rows = append(rows, []string{"forecast", period.Name, "", period.StartTime, strconv.Itoa(period.Temperature),
+			period.TemperatureUnit, "", "", "", "", "", period.DetailedForecast})
+	}
+	for _, period := range r.Hourly {
+		rows = append(rows, []string{"hourly", period.Name, "", period.StartTime, strconv.Itoa(period.Temperature),
+			period.TemperatureUnit, "", "", "", "", "", period.ShortForecast})
+	}
+	return rows
+}
+
+// parseFloat parses a coordinate, returning 0 if it isn't a number.
+func parseFloat(value string) float64 {
+	number, _ := strconv.ParseFloat(value, 64)
+	return number
+}
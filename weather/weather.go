@@ -0,0 +1,428 @@
+// Package weather geocodes addresses with the U.S. Census Geocoding API and
+// fetches observations and forecasts from the NOAA weather API.
+// Neither API requires an API key.
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"polyapi/httpx"
+	"polyapi/pool"
+)
+
+type GeoCodingResponse struct {
+	Result struct {
+		AddressMatches []struct {
+			Coordinates struct {
+				X float64 `json:"x"`
+				Y float64 `json:"y"`
+			} `json:"coordinates"`
+			MatchedAddress string `json:"matchedAddress"`
+		} `json:"addressMatches"`
+	} `json:"result"`
+}
+
+type Measurement struct {
+	UnitCode       string   `json:"unitCode"`
+	Value          *float64 `json:"value"`
+	QualityControl string   `json:"qualityControl"`
+}
+
+type CloudLayer struct {
+	Base   Measurement `json:"base"`
+	Amount string      `json:"amount"`
+}
+
+type Properties struct {
+	ID                        string        `json:"@id"`
+	Type                      string        `json:"@type"`
+	Elevation                 Measurement   `json:"elevation"`
+	Station                   string        `json:"station"`
+	Timestamp                 string        `json:"timestamp"`
+	RawMessage                string        `json:"rawMessage"`
+	TextDescription           string        `json:"textDescription"`
+	Icon                      string        `json:"icon"`
+	PresentWeather            []interface{} `json:"presentWeather"`
+	Temperature               Measurement   `json:"temperature"`
+	Dewpoint                  Measurement   `json:"dewpoint"`
+	WindDirection             Measurement   `json:"windDirection"`
+	WindSpeed                 Measurement   `json:"windSpeed"`
+	WindGust                  Measurement   `json:"windGust"`
+	BarometricPressure        Measurement   `json:"barometricPressure"`
+	SeaLevelPressure          Measurement   `json:"seaLevelPressure"`
+	Visibility                Measurement   `json:"visibility"`
+	MaxTemperatureLast24Hours Measurement   `json:"maxTemperatureLast24Hours"`
+	MinTemperatureLast24Hours Measurement   `json:"minTemperatureLast24Hours"`
+	PrecipitationLastHour     Measurement   `json:"precipitationLastHour"`
+	PrecipitationLast3Hours   Measurement   `json:"precipitationLast3Hours"`
+	PrecipitationLast6Hours   Measurement   `json:"precipitationLast6Hours"`
+	RelativeHumidity          Measurement   `json:"relativeHumidity"`
+	WindChill                 Measurement   `json:"windChill"`
+	HeatIndex                 Measurement   `json:"heatIndex"`
+	CloudLayers               []CloudLayer  `json:"cloudLayers"`
+}
+
+type Geometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+type Station struct {
+	Geometry   Geometry `json:"geometry"`
+	Properties struct {
+		Name              string `json:"name"`
+		StationIdentifier string `json:"stationIdentifier"`
+	} `json:"properties"`
+}
+
+type StationsResponse struct {
+	Features []Station `json:"features"`
+}
+
+type Observation struct {
+	Properties Properties `json:"properties"`
+}
+
+// PointsResponse is the NOAA points metadata for a location.
+// The forecast endpoints return the same shape with Periods filled in.
+type PointsResponse struct {
+	Properties struct {
+		Forecast            string `json:"forecast"`
+		ForecastHourly      string `json:"forecastHourly"`
+		ForecastGridData    string `json:"forecastGridData"`
+		ObservationStations string `json:"observationStations"`
+		ForecastZone        string `json:"forecastZone"`
+		County              string `json:"county"`
+		FireWeatherZone     string `json:"fireWeatherZone"`
+		Periods             []struct {
+			Number           int    `json:"number"`
+			Name             string `json:"name"`
+			StartTime        string `json:"startTime"`
+			EndTime          string `json:"endTime"`
+			IsDaytime        bool   `json:"isDaytime"`
+			Temperature      int    `json:"temperature"`
+			TemperatureUnit  string `json:"temperatureUnit"`
+			ShortForecast    string `json:"shortForecast"`
+			DetailedForecast string `json:"detailedForecast"`
+		} `json:"periods"`
+	} `json:"properties"`
+}
+
+// Match is the address matched by the Census Geocoding API with its coordinates.
+type Match struct {
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// GeographiesResponse is the Census geographies of a location, by layer name.
+type GeographiesResponse struct {
+	Result struct {
+		Geographies map[string][]struct {
+			GEOID  string `json:"GEOID"`
+			Name   string `json:"NAME"`
+			State  string `json:"STATE"`
+			STUSAB string `json:"STUSAB"`
+			CBSA   string `json:"CBSA"`
+		} `json:"geographies"`
+	} `json:"result"`
+}
+
+// Region is the state and metropolitan statistical area of a location: the state's
+// FIPS code and abbreviation, and the metro area's CBSA code and name, which are empty
+// outside of a metro area.
+type Region struct {
+	StateFIPS string `json:"state_fips"`
+	State     string `json:"state"`
+	CBSA      string `json:"cbsa,omitempty"`
+	Metro     string `json:"metro,omitempty"`
+}
+
+// regionLayers are the Census layers with a location's state and metro area.
+const regionLayers = "States,Metropolitan Statistical Areas"
+
+const (
+	// DefaultGeocoderURL is the base URL of the Census Geocoding API.
+	DefaultGeocoderURL = "https://geocoding.geo.census.gov"
+	// DefaultBaseURL is the base URL of the NOAA weather API.
+	DefaultBaseURL = "https://api.weather.gov"
+)
+
+// Client calls the Census Geocoding and NOAA weather APIs.
+// The base URLs can be changed to point the client at another server, e.g. in tests.
+type Client struct {
+	HTTPClient  *http.Client
+	GeocoderURL string
+	BaseURL     string
+}
+
+// NewClient returns a weather client for the Census and NOAA APIs.
+func NewClient() *Client {
+	return &Client{
+		HTTPClient:  httpx.DefaultClient,
+		GeocoderURL: DefaultGeocoderURL,
+		BaseURL:     DefaultBaseURL,
+	}
+}
+
+// getJSON sends a GET request and decodes the JSON response into dest.
+func (c *Client) getJSON(ctx context.Context, url string, dest interface{}) error {
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	if err := httpx.CheckResponse(resp); err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, dest)
+}
+
+// Geocode sends a request to the Census Geocoding API to get the coordinates of an address.
+func (c *Client) Geocode(ctx context.Context, address string) (Match, error) {
+
+	var match Match
+
+	address = strings.TrimSpace(address)
+
+	// Construct the API request
+	url := fmt.Sprintf("%s/geocoder/locations/onelineaddress?address=%s&benchmark=4&format=json", c.GeocoderURL, url.QueryEscape(address))
+
+	var response GeoCodingResponse
+	if err := c.getJSON(ctx, url, &response); err != nil {
+		return match, err
+	}
+
+	if len(response.Result.AddressMatches) == 0 {
+		return match, fmt.Errorf("no coordinates found")
+	}
+
+	match.Address = response.Result.AddressMatches[0].MatchedAddress
+	match.Latitude = response.Result.AddressMatches[0].Coordinates.Y
+	match.Longitude = response.Result.AddressMatches[0].Coordinates.X
+
+	return match, nil
+}
+
+// Region sends a request to the Census Geocoding API to get the state and metro area of a location.
+func (c *Client) Region(ctx context.Context, lat, lon float64) (Region, error) {
+
+	var region Region
+
+	url := fmt.Sprintf("%s/geocoder/geographies/coordinates?x=%f&y=%f&benchmark=4&vintage=4&layers=%s&format=json",
+		c.GeocoderURL, lon, lat, url.QueryEscape(regionLayers))
+
+	var response GeographiesResponse
+	if err := c.getJSON(ctx, url, &response); err != nil {
+		return region, err
+	}
+
+	states := response.Result.Geographies["States"]
+	if len(states) == 0 {
+		return region, fmt.Errorf("no state found")
+	}
+	region.StateFIPS = states[0].State
+	region.State = states[0].STUSAB
+
+	if metros := response.Result.Geographies["Metropolitan Statistical Areas"]; len(metros) > 0 {
+		region.CBSA = metros[0].CBSA
+		region.Metro = metros[0].Name
+	}
+
+	return region, nil
+}
+
+// NearestStations fetches the nearest observation stations and returns their information
+func (c *Client) NearestStations(ctx context.Context, lat, lon string) ([]Station, error) {
+
+	url := fmt.Sprintf("%s/points/%s,%s/stations", c.BaseURL, lat, lon)
+
+	var stationsResponse StationsResponse
+	if err := c.getJSON(ctx, url, &stationsResponse); err != nil {
+		return nil, err
+	}
+
+	// The 4 closest stations
+	closestStations := stationsResponse.Features
+	if len(closestStations) > 4 {
+		closestStations = closestStations[:4]
+	}
+	return closestStations, nil
+}
+
+// Observation fetches the latest observation data for a specific station
+func (c *Client) Observation(ctx context.Context, stationID string) (Observation, error) {
+
+	url := fmt.Sprintf("%s/stations/%s/observations/latest", c.BaseURL, stationID)
+
+	var observation Observation
+	if err := c.getJSON(ctx, url, &observation); err != nil {
+		return Observation{}, err
+	}
+
+	return observation, nil
+}
+
+// Points fetches the NOAA points metadata with the forecast URLs for a location.
+func (c *Client) Points(ctx context.Context, lat, lon string) (PointsResponse, error) {
+
+	url := fmt.Sprintf("%s/points/%s,%s", c.BaseURL, lat, lon)
+
+	var points PointsResponse
+	err := c.getJSON(ctx, url, &points)
+	return points, err
+}
+
+// ForecastPeriods calls a NOAA forecast or hourly forecast URL and returns its periods.
+func (c *Client) ForecastPeriods(ctx context.Context, forecastURL string) ([]ForecastPeriod, error) {
+
+	var forecast PointsResponse
+	if err := c.getJSON(ctx, forecastURL, &forecast); err != nil {
+		return nil, err
+	}
+
+	// Check if Periods array is not empty
+	if len(forecast.Properties.Periods) == 0 {
+		return nil, fmt.Errorf("no forecast periods available")
+	}
+
+	var periods []ForecastPeriod
+	for _, period := range forecast.Properties.Periods {
+		periods = append(periods, ForecastPeriod{
+			Name:             period.Name,
+			StartTime:        period.StartTime,
+			Temperature:      period.Temperature,
+			TemperatureUnit:  period.TemperatureUnit,
+			ShortForecast:    period.ShortForecast,
+			DetailedForecast: period.DetailedForecast,
+		})
+	}
+
+	return periods, nil
+}
+
+// Forecast returns the weather forecast for a location.
+// Specifically, it returns the next 4 periods and the last 2 periods.
+// A period is a 12-hour time frame.
+func (c *Client) Forecast(ctx context.Context, points PointsResponse) ([]ForecastPeriod, error) {
+
+	periods, err := c.ForecastPeriods(ctx, points.Properties.Forecast)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(periods) <= 6 {
+		return periods, nil
+	}
+
+	// The next 4 periods and the last 2 periods
+	forecast := append([]ForecastPeriod{}, periods[:4]...)
+	return append(forecast, periods[len(periods)-2:]...), nil
+}
+
+// HourlyForecast returns the hourly weather forecast for a location.
+// Specifically, it returns the next 12 hours.
+func (c *Client) HourlyForecast(ctx context.Context, points PointsResponse) ([]ForecastPeriod, error) {
+
+	periods, err := c.ForecastPeriods(ctx, points.Properties.ForecastHourly)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(periods) > 12 {
+		periods = periods[:12]
+	}
+
+	return periods, nil
+}
+
+// CurrentTemperature returns the first hourly temperature from the hourly forecast, e.g. "72F".
+func (c *Client) CurrentTemperature(ctx context.Context, points PointsResponse) (string, error) {
+
+	periods, err := c.ForecastPeriods(ctx, points.Properties.ForecastHourly)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d%s", periods[0].Temperature, periods[0].TemperatureUnit), nil
+}
+
+// Report gets observations from the nearest NOAA weather stations for a location.
+// It also returns the NOAA points response so callers can fetch forecasts.
+func (c *Client) Report(ctx context.Context, lat, lon string) (Report, PointsResponse, error) {
+
+	var points PointsResponse
+
+	report := Report{
+		Latitude:  parseFloat(lat),
+		Longitude: parseFloat(lon),
+		MapsURL:   GoogleMapsURL(lat, lon),
+	}
+
+	// Fetch nearest stations
+	stations, err := c.NearestStations(ctx, lat, lon)
+	if err != nil {
+		return report, points, fmt.Errorf("error fetching nearest stations: %w", err)
+	}
+
+	// Observation data for the closest stations, fetched concurrently and sorted by nearest to farthest
+	report.Stations = make([]StationObservation, len(stations))
+	err = pool.Run(ctx, pool.Size(len(stations)), len(stations), func(ctx context.Context, i int) {
+
+		station := stations[i]
+		var stationObservation StationObservation
+		observation, err := c.Observation(ctx, station.Properties.StationIdentifier)
+		if err != nil {
+			stationObservation.Error = err.Error()
+		} else {
+			stationObservation = newStationObservation(observation.Properties)
+		}
+		stationObservation.Name = station.Properties.Name
+		stationObservation.Identifier = station.Properties.StationIdentifier
+		if len(station.Geometry.Coordinates) == 2 {
+			lat := station.Geometry.Coordinates[1]
+			lon := station.Geometry.Coordinates[0]
+			stationObservation.MapsURL = GoogleMapsURL(fmt.Sprintf("%f", lat), fmt.Sprintf("%f", lon))
+		}
+
+		report.Stations[i] = stationObservation
+	})
+	if err != nil {
+		return report, points, err
+	}
+
+	points, err = c.Points(ctx, lat, lon)
+	if err != nil {
+		return report, points, err
+	}
+
+	return report, points, nil
+}
+
+// GoogleMapsURL returns a Google Maps search link for a location.
+func GoogleMapsURL(lat, lon string) string {
+	return fmt.Sprintf("https://www.google.com/maps/search/?api=1&query=%s,%s", lat, lon)
+}
+
+// Helper function to convert Celsius to Fahrenheit
+func celsiusToFahrenheit(celsius float64) float64 {
+	return (celsius * 9 / 5) + 32
+}
@@ -0,0 +1,113 @@
+package weather
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// newTestClient returns a client for a server that serves the fixture in testdata
+// for each path in routes.
+func newTestClient(t *testing.T, routes map[string]string) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", fixture))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient()
+	client.HTTPClient = server.Client()
+	client.BaseURL = server.URL
+	client.GeocoderURL = server.URL
+	return client
+}
+
+func TestNearestStations(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"/points/40.7614,-73.9712/stations": "stations.json",
+	})
+
+	stations, err := client.NearestStations(context.Background(), "40.7614", "-73.9712")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the 4 closest of the 5 stations are returned
+	want := []string{"KNYC", "KLGA", "KJRB", "KEWR"}
+	if len(stations) != len(want) {
+		t.Fatalf("got %d stations, want %d", len(stations), len(want))
+	}
+	for i, id := range want {
+		if got := stations[i].Properties.StationIdentifier; got != id {
+			t.Errorf("station %d = %s, want %s", i, got, id)
+		}
+	}
+	if got := stations[0].Geometry.Coordinates; len(got) != 2 || got[1] != 40.77898 {
+		t.Errorf("station coordinates = %v", got)
+	}
+}
+
+func TestObservation(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"/stations/KNYC/observations/latest": "observation.json",
+	})
+
+	observation, err := client.Observation(context.Background(), "KNYC")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	properties := observation.Properties
+	if properties.TextDescription != "Mostly Clear" {
+		t.Errorf("TextDescription = %q", properties.TextDescription)
+	}
+	if properties.Temperature.Value == nil || *properties.Temperature.Value != 24.4 {
+		t.Errorf("Temperature = %v, want 24.4", properties.Temperature.Value)
+	}
+	if properties.WindGust.Value != nil {
+		t.Errorf("WindGust = %v, want nil", *properties.WindGust.Value)
+	}
+
+	// Temperatures are converted to Fahrenheit and missing measurements dropped
+	summary := newStationObservation(properties)
+	if summary.TemperatureF == nil || math.Abs(*summary.TemperatureF-75.92) > 0.01 {
+		t.Errorf("TemperatureF = %v, want 75.92", summary.TemperatureF)
+	}
+	if summary.WindDirection != nil {
+		t.Errorf("WindDirection = %v, want nil", *summary.WindDirection)
+	}
+}
+
+func TestObservationNotFound(t *testing.T) {
+	client := newTestClient(t, map[string]string{})
+
+	if _, err := client.Observation(context.Background(), "KNYC"); err == nil {
+		t.Error("expected an error for a missing station")
+	}
+}
+
+func TestRegion(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"/geocoder/geographies/coordinates": "geographies.json",
+	})
+
+	region, err := client.Region(context.Background(), 40.7614, -73.9712)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if region.StateFIPS != "36" || region.State != "NY" || region.CBSA != "35620" {
+		t.Errorf("region = %+v", region)
+	}
+	if region.Metro != "New York-Newark-Jersey City, NY-NJ-PA Metro Area" {
+		t.Errorf("metro = %q", region.Metro)
+	}
+}